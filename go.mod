@@ -2,11 +2,14 @@ module github.com/Tusamarco/mysqloperatorcalculator
 
 go 1.21
 
-require github.com/sirupsen/logrus v1.9.3
+require (
+	code.cloudfoundry.org/bytefmt v0.0.0-20230612151507-41ef4d1f67a4
+	github.com/hashicorp/go-version v1.6.0
+	github.com/sirupsen/logrus v1.9.3
+	gopkg.in/yaml.v3 v3.0.1
+)
 
 require (
-	code.cloudfoundry.org/bytefmt v0.0.0-20230612151507-41ef4d1f67a4 // indirect
-	github.com/hashicorp/go-version v1.6.0 // indirect
 	github.com/inhies/go-bytesize v0.0.0-20220417184213-4913239db9cf // indirect
 	golang.org/x/sys v0.13.0 // indirect
 )
@@ -33,11 +33,12 @@ func (help *HelpText) PrintLicense() {
 }
 
 func (help *HelpText) GetHelpText() string {
-	helpText := `MySQL Operator Calculator — Percona (PXC and Group Replication)
+	helpText := `MySQL Operator Calculator — Percona (PXC, Group Replication and async replication)
 
 ENDPOINTS
   GET  /supported    Returns valid dimensions, load types, DB types, and MySQL version range.
   POST /calculator   Returns a full MySQL / Kubernetes configuration for the given request.
+  POST /audit        Checks an existing my.cnf against the dimension of the given request.
 
 ────────────────────────────────────────────────────────────────
 GET /supported
@@ -46,8 +47,9 @@ GET /supported
 
   Response (abbreviated):
   {
-    "DBType":  ["group_replication", "pxc"],
-    "Output":  ["human", "json"],
+    "DBType":  ["group_replication", "pxc", "async"],
+    "ProxyType": ["haproxy", "proxysql", "router"],
+    "Output":  ["human", "json", "mycnf", "pxc_cr", "ps_cr", "haproxy_cfg"],
     "Dimension": [
       {"Id":1,  "Name":"XSmall",   "Cpu":1000,  "Memory":"2GB"},
       {"Id":2,  "Name":"Small",    "Cpu":2500,  "Memory":"4GB"},
@@ -66,7 +68,8 @@ GET /supported
       {"Id":1,"Name":"Mainly Reads", "Description":"Blogs ~10% Writes 90% Reads"},
       {"Id":2,"Name":"Light OLTP",   "Description":"Shops online up to ~40% Writes"},
       {"Id":3,"Name":"Heavy OLTP",   "Description":"Intense analytics, telephony, gaming. 30/70% Reads and Writes"},
-      {"Id":4,"Name":"Mainly write", "Description":"Data load, data ingest. 90% writes"}
+      {"Id":4,"Name":"Mainly write", "Description":"Data load, data ingest. 90% writes"},
+      {"Id":5,"Name":"Analytics",    "Description":"Reporting replicas, BI. Few connections running large scans, joins and sorts"}
     ],
     "Connections":   [50,100,200,500,1000,2000],
     "Mysqlversions": {"Min":{"Major":8,"Minor":0,"Patch":46},"Max":{"Major":11,"Minor":1,"Patch":1}}
@@ -100,6 +103,23 @@ POST /calculator — open dimension (id 999, custom CPU + memory)
         }' \
     http://127.0.0.1:8080/calculator
 
+────────────────────────────────────────────────────────────────
+POST /calculator — cloud instance type (bundled catalog)
+────────────────────────────────────────────────────────────────
+  The node shape replaces the dimension: kubelet reservations and the
+  provider DaemonSets are deducted and the rest sized as an open request.
+
+  curl -X POST -H "Content-Type: application/json" \
+    -d '{
+          "output":       "json",
+          "dbtype":       "pxc",
+          "instancetype": "m6i.2xlarge",
+          "loadtype":     {"id": 2},
+          "connections":  300,
+          "mysqlversion": {"major":8,"minor":0,"patch":46}
+        }' \
+    http://127.0.0.1:8080/calculator
+
 ────────────────────────────────────────────────────────────────
 POST /calculator — connection-driven sizing (id 998)
 ────────────────────────────────────────────────────────────────
@@ -117,16 +137,100 @@ POST /calculator — connection-driven sizing (id 998)
         }' \
     http://127.0.0.1:8080/calculator
 
+────────────────────────────────────────────────────────────────
+POST /audit — check an existing my.cnf
+────────────────────────────────────────────────────────────────
+  The [mysqld] values are pinned and run through the same memory
+  accounting; the message holds the verdict (Ok, Close to limit,
+  Overutilizing) and findings list the values over max or below min,
+  not valid for mysqlversion, unmanaged, or diverging from the
+  recommendation. Without connections max_connections is used.
+
+  curl -X POST -H "Content-Type: application/json" \
+    -d '{
+          "output":       "json",
+          "dbtype":       "pxc",
+          "dimension":    {"id": 4},
+          "loadtype":     {"id": 2},
+          "mysqlversion": {"major":8,"minor":0,"patch":46},
+          "mycnf":        "[mysqld]\nmax_connections=302\ninnodb_buffer_pool_size=10G\n"
+        }' \
+    http://127.0.0.1:8080/audit
+
 ────────────────────────────────────────────────────────────────
 REQUEST FIELDS
 ────────────────────────────────────────────────────────────────
-  output          "human" (INI-style my.cnf) or "json"
-  dbtype          "pxc" or "group_replication"
+  output          "human" (INI-style review text), "json", "mycnf" ([mysqld] option file),
+                  "pxc_cr" (PerconaXtraDBCluster YAML), "ps_cr" (PerconaServerMySQL YAML,
+                  group_replication only) or "haproxy_cfg" (haproxy.cfg stanzas)
+  dbtype          "pxc", "group_replication" or "async" (source/replica)
+  proxytype       optional "haproxy" (default), "proxysql" or "router" (group_replication only)
   dimension.id    1–10 predefined  |  998 connection-driven  |  999 custom resources
+  instancetype    optional cloud node shape from /supported (e.g. "n2-standard-8"), replaces dimension
   loadtype.id     1 Mainly Reads   |  2 Light OLTP  |  3 Heavy OLTP  |  4 Mainly write
+                  5 Analytics (reporting replicas: few connections, large buffers, parallel reads)
+  nodes           optional cluster members (pxc and group_replication: 3, 5, 7 or 9; async: source plus
+                  replicas), default 3: flow control, suspect/expel timeouts and proxy failover sizing
+  mode            optional "single-primary" (default) or "multi-primary"
+  consistency     optional group_replication_consistency: EVENTUAL, BEFORE_ON_PRIMARY_FAILOVER, BEFORE,
+                  AFTER or BEFORE_AND_AFTER; it or a mode sets the flow control thresholds and the
+                  transaction size limit for the load, warnings in message.consistency
+  network         optional {"rttMs":ms,"segments":[n,..],"segment":i}: round trip between the members
+                  and members per datacenter, galera timeouts, send windows and gmcast.segment, group
+                  replication expel, message size and majority timeouts; commit penalty in message.network
+  writeratio      optional share of writes 0.0–1.0: the load values are interpolated between the
+                  load types (5%, 20%, 50%, 90% writes), loadtype.id may then be omitted
   connections     target connection count (0 = auto-discover maximum for the dimension)
   mysqlversion    {"major":M,"minor":m,"patch":p}  minimum supported: 8.0.46
-  providerCostPct optional overhead fraction deducted from resources (e.g. 0.12 = 12%)
+  provider        optional "eks", "gke", "aks", "openshift" or "bare-metal": deducts the platform
+                  reservations from an open (999) dimension or an instancetype node
+  providerCostPct optional legacy overhead fraction deducted from resources (e.g. 0.12 = 12%),
+                  not allowed with provider or instancetype
+  explain         optional true: every calculated parameter carries the rule, inputs, branch and
+                  clamping that produced it (json output)
+  overrides       optional {"name":"value"} pinned parameters, checked against min/max and version,
+                  the memory is balanced around them and the output flags them as pinned;
+                  pxc provider options by name, durations as seconds/minutes or "PT30S", "PT1M"
+  throughput      optional {"readsPerSec":r,"writesPerSec":w,"avgWriteBytes":b,"redoMinutes":m}: sizes
+                  cpu, connections, redo log, gcache and innodb_io_capacity for the target rate,
+                  the binding constraint is reported in message.throughput
+  ist             optional {"windowMinutes":m,"writeBytesPerSec":b}: pxc gcache.size holding m minutes of
+                  writes (the throughput rate when b is not set), cut to what the memory allows,
+                  the achievable window is reported in message.ist
+  storage         optional {"datasetSize":b,"growthPerDay":b,"growthDays":d,"iops":n,"throughputMBps":m,
+                  "binlogPerDay":b}: recommended PVC (data, redo log, binlog, gcache, temp) in
+                  message.storage, innodb io capacity, flush neighbors and io threads from the iops
+                  "hotSetPct":0.0–1.0 caps the buffer pool at the hot set, "releaseMemory":true
+                  recalculates on the smaller dimension the hot set needs
+  globalstatus    optional captured SHOW GLOBAL STATUS (tab separated, table or JSON): sets writeratio,
+                  loadtype, connections and tmp_table_size when the request leaves them unset, the
+                  derivation is returned in incoming.statusprofile
+
+────────────────────────────────────────────────────────────────
+SERVER FLAGS
+────────────────────────────────────────────────────────────────
+  -port           port to serve, default 8080
+  -address        ip address to bind, default 0.0.0.0
+  -loglevel       ERROR, INFO or DEBUG (default)
+  -dimensions     dimension catalog file (.json, .yaml or .yml) replacing the built-in
+                  dimensions 1–10; 998 and 999 stay available. Ids must be unique and
+                  positive, cpu and memory must grow with each entry and the mysql, proxy
+                  and pmm shares must fit in the totals. The server does not start with
+                  an invalid catalog; /supported lists the loaded dimensions.
+
+  ./mysqloperatorcalculator -dimensions nodepools.yaml
+
+  dimensions:
+    - id: 1
+      name: pool-small
+      cpu: 3000
+      memory: 6GB
+      mysqlCpu: 2400
+      proxyCpu: 400
+      pmmCpu: 200
+      mysqlMemory: 5GB
+      proxyMemory: 512MB
+      pmmMemory: 256MB
 
 `
 	return helpText
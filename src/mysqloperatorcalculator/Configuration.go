@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"sort"
+	"strings"
 
 	"code.cloudfoundry.org/bytefmt"
 )
@@ -40,29 +41,126 @@ type MySQLVersions struct {
 }
 
 type ResponseMessage struct {
-	MType int    `json:"type"`
-	MName string `json:"name"`
-	MText string `json:"text"`
+	MType     int                `json:"type"`
+	MName     string             `json:"name"`
+	MText     string             `json:"text"`
+	Breakdown *ResourceBreakdown `json:"breakdown,omitempty"`
+	Reports   Reports            `json:"reports,omitempty"`
+}
+
+// Report names, one for each request input answered by a report
+const (
+	ReportThroughput  = "throughput"
+	ReportStorage     = "storage"
+	ReportWorkingSet  = "workingset"
+	ReportNetwork     = "network"
+	ReportIST         = "ist"
+	ReportConsistency = "consistency"
+)
+
+// Report is the typed answer to one request input, its text is added to the message text
+type Report interface {
+	text() string
+}
+
+type Reports map[string]Report
+
+// addReport sets the report by name and adds its text to the message text
+func (m *ResponseMessage) addReport(name string, report Report) {
+	if m.Reports == nil {
+		m.Reports = make(Reports)
+	}
+	m.Reports[name] = report
+	m.MText += report.text()
+}
+
+// ResourceBreakdown is the typed form of the resource details reported in the message text
+type ResourceBreakdown struct {
+	Connections   int             `json:"connections"`
+	LoadFactor    float64         `json:"loadFactor"`
+	BufferPoolPct float64         `json:"bufferPoolPct"`
+	Cpu           CpuBreakdown    `json:"cpu"`
+	Memory        MemoryBreakdown `json:"memory"`
+}
+
+// CpuBreakdown is the split of the dimension CPU, in millicores
+type CpuBreakdown struct {
+	Total int `json:"total"`
+	Mysql int `json:"mysql"`
+	Proxy int `json:"proxy"`
+	Pmm   int `json:"pmm"`
+}
+
+// MemoryBreakdown is the split of the dimension memory, in bytes
+type MemoryBreakdown struct {
+	Total int64       `json:"total"`
+	Mysql MysqlMemory `json:"mysql"`
+	Proxy int64       `json:"proxy"`
+	Pmm   int64       `json:"pmm"`
+}
+
+// MysqlMemory details what the consumers inside mysqld take from the mysql share and what is left over.
+// Gcache applies to pxc only, GcsCache to group_replication only
+type MysqlMemory struct {
+	Allocated         int64 `json:"allocated"`
+	BufferPool        int64 `json:"bufferPool"`
+	ConnectionBuffers int64 `json:"connectionBuffers"`
+	TmpTableFootprint int64 `json:"tmpTableFootprint"`
+	Gcache            int64 `json:"gcache,omitempty"`
+	GcacheFootprint   int64 `json:"gcacheFootprint,omitempty"`
+	GcsCache          int64 `json:"gcsCache,omitempty"`
+	GcsCacheFootprint int64 `json:"gcsCacheFootprint,omitempty"`
+	Leftover          int64 `json:"leftover"`
 }
 
 type Configuration struct {
-	DBType          []string      `json:"dbtype"`
-	Dimension       []Dimension   `json:"dimension"`
-	LoadType        []LoadType    `json:"loadtype"`
-	Connections     []int         `json:"connections"`
-	Output          []string      `json:"output"`
-	Mysqlversions   MySQLVersions `json:"mysqlversions"`
-	ProviderCostPct float64       `json:"providercostpct"`
+	DBType          []string        `json:"dbtype"`
+	ProxyType       []string        `json:"proxytype"`
+	Dimension       []Dimension     `json:"dimension"`
+	LoadType        []LoadType      `json:"loadtype"`
+	Connections     []int           `json:"connections"`
+	Output          []string        `json:"output"`
+	Mysqlversions   MySQLVersions   `json:"mysqlversions"`
+	ProviderCostPct float64         `json:"providercostpct"`
+	Providers       []string        `json:"providers"`
+	InstanceTypes   InstanceCatalog `json:"instancetypes"`
 }
 
 type ConfigurationRequest struct {
 	DBType          string    `json:"dbtype"`
+	ProxyType       string    `json:"proxytype,omitempty"`
 	Dimension       Dimension `json:"dimension"`
 	LoadType        LoadType  `json:"loadtype"`
 	Connections     int       `json:"connections"`
 	Output          string    `json:"output"`
 	Mysqlversion    Version   `json:"mysqlversion"`
 	ProviderCostPct float64   `json:"providercostpct"`
+	InstanceType    string    `json:"instancetype,omitempty"`
+	Provider        string    `json:"provider,omitempty"`
+	Explain         bool      `json:"explain,omitempty"`
+	// Nodes and Mode describe the cluster, DefaultClusterNodes single-primary when not set, see topology.go
+	Nodes int    `json:"nodes,omitempty"`
+	Mode  string `json:"mode,omitempty"`
+	// Consistency is group_replication_consistency, the one of the mode when not set, see consistency.go
+	Consistency string `json:"consistency,omitempty"`
+	// WriteRatio (0.0-1.0) is the share of writes, when set the load values are interpolated between the load types
+	// and LoadType.Id, if not set, is the load type with the closest share of writes
+	WriteRatio *float64 `json:"writeratio,omitempty"`
+	// Throughput sizes the pod for target reads/writes per second, see throughput.go
+	Throughput *Throughput `json:"throughput,omitempty"`
+	// Storage sizes the PVC and the InnoDB I/O from the dataset and the storage class, see storage.go
+	Storage *Storage `json:"storage,omitempty"`
+	// Network tunes the group for the round trip between the members and their datacenters, see network.go
+	Network *Network `json:"network,omitempty"`
+	// IST sizes GCache for the minutes a PXC node may be down and still rejoin without SST, see ist.go
+	IST *IST `json:"ist,omitempty"`
+	// Overrides pins parameters by name to the given value, see overrides.go
+	Overrides map[string]string `json:"overrides,omitempty"`
+	// GlobalStatus is a captured SHOW GLOBAL STATUS (text or JSON), MysqlOperatorCalculator.Init replaces it with StatusProfile
+	GlobalStatus  string         `json:"globalstatus,omitempty"`
+	StatusProfile *StatusProfile `json:"statusprofile,omitempty"`
+	// ProviderDeductions is filled by MysqlOperatorCalculator.Init when a provider profile sized the dimension
+	ProviderDeductions *ProviderDeductions `json:"providerdeductions,omitempty"`
 }
 
 type Dimension struct {
@@ -96,13 +194,19 @@ type Parameter struct {
 	Mysqlversions MySQLVersions `json:"mysqlversions"`
 }
 
-func (p Parameter) MarshalJSON() ([]byte, error) {
-	// Create an inline anonymous struct with only the fields you want
+// parameterOutput is a parameter as the json output shows it, the group adds the pinned flag and the trace
+type parameterOutput struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Pinned bool   `json:"pinned,omitempty"`
+	// PinnedOptions are the pinned options of a parameter holding several (wsrep-provider-options)
+	PinnedOptions []string                   `json:"pinnedOptions,omitempty"`
+	Options       map[string]parameterOutput `json:"options,omitempty"`
+	Explain       *ParameterTrace            `json:"explain,omitempty"`
+}
 
-	return json.Marshal(&struct {
-		Name  string `json:"name"`
-		Value string `json:"value"`
-	}{
+func (p Parameter) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&parameterOutput{
 		Name:  p.Name,
 		Value: p.Value,
 	})
@@ -111,6 +215,58 @@ func (p Parameter) MarshalJSON() ([]byte, error) {
 type GroupObj struct {
 	Name       string               `yaml:"name" json:"name"`
 	Parameters map[string]Parameter `yaml:"parameters" json:"parameters"`
+	// Traces are set by the Configurator, by parameter key, for the pinned parameters and with explain for all it sets
+	Traces map[string]*ParameterTrace `yaml:"-" json:"-"`
+	// Options are the options of the parameters holding several (wsrep-provider-options), by parameter key and option name
+	Options map[string]map[string]Parameter `yaml:"-" json:"-"`
+}
+
+func (g GroupObj) MarshalJSON() ([]byte, error) {
+	parameters := make(map[string]parameterOutput, len(g.Parameters))
+	for key, p := range g.Parameters {
+		output := parameterOutput{Name: p.Name, Value: p.Value}
+		if trace := g.Traces[key]; trace != nil {
+			output.Pinned = trace.Pinned
+			output.PinnedOptions = trace.PinnedOptions
+			// Without explain a pinned parameter carries a trace with no rule, only the pinned flag is output
+			if trace.Rule != "" {
+				output.Explain = trace
+			}
+		}
+		if options := g.Options[key]; len(options) > 0 {
+			output.Options = make(map[string]parameterOutput, len(options))
+			for name, option := range options {
+				output.Options[name] = parameterOutput{Name: option.Name, Value: option.Value, Pinned: g.IsPinnedOption(key, name)}
+			}
+		}
+		parameters[key] = output
+	}
+
+	return json.Marshal(&struct {
+		Name       string                     `json:"name"`
+		Parameters map[string]parameterOutput `json:"parameters"`
+	}{
+		Name:       g.Name,
+		Parameters: parameters,
+	})
+}
+
+// IsPinned reports if the value of the parameter key comes from ConfigurationRequest.Overrides instead of the calculation
+func (g GroupObj) IsPinned(key string) bool {
+	trace := g.Traces[key]
+	return trace != nil && trace.Pinned
+}
+
+// IsPinnedOption reports if the option of the parameter key (gcache.size of wsrep-provider-options) comes from ConfigurationRequest.Overrides
+func (g GroupObj) IsPinnedOption(key string, option string) bool {
+	if trace := g.Traces[key]; trace != nil {
+		for _, pinned := range trace.PinnedOptions {
+			if pinned == option {
+				return true
+			}
+		}
+	}
+	return false
 }
 
 type Family struct {
@@ -156,9 +312,35 @@ func (respM *ResponseMessage) GetMessageText(id int) string {
 }
 
 func (conf *Configuration) Init() {
-	conf.DBType = []string{DbTypeGroupReplication, DbTypePXC}
-	conf.Output = []string{ResultOutputFormatHuman, ResultOutputFormatJson}
-	conf.Dimension = []Dimension{
+	conf.DBType = []string{DbTypeGroupReplication, DbTypePXC, DbTypeAsync}
+	conf.ProxyType = []string{ProxyTypeHAProxy, ProxyTypeProxySQL, ProxyTypeRouter}
+	conf.Output = []string{ResultOutputFormatHuman, ResultOutputFormatJson, ResultOutputFormatMyCnf, ResultOutputFormatPXCCR, ResultOutputFormatPSCR, ResultOutputFormatHAProxyCfg}
+	conf.Dimension = getDimensionCatalog()
+	if conf.Dimension == nil {
+		conf.Dimension = builtinDimensions()
+	}
+	conf.Dimension = append(conf.Dimension,
+		Dimension{DimensionOpen, "Open request by resources", 0, "0GB", 0, 0, 0, 0, 0, 0, 0},
+		Dimension{ConnectionDimension, "Open request by Connection", 0, "0GB", 0, 0, 0, 0, 0, 0, 0},
+	)
+
+	conf.LoadType = []LoadType{
+		{1, "Mainly Reads", "Blogs ~10% Writes 90% Reads"},
+		{2, "Light OLTP", "Shops online up to ~40% Writes "},
+		{3, "Heavy OLTP", "Intense analytics, telephony, gaming. 30/70% Reads and Writes"},
+		{4, "Mainly write", "Data load, data ingest. 90% writes"},
+		{5, "Analytics", "Reporting replicas, BI. Few connections running large scans, joins and sorts"},
+	}
+
+	conf.Connections = []int{50, 100, 200, 500, 1000, 2000}
+	conf.Providers = providerNames()
+	conf.InstanceTypes = GetInstanceCatalog()
+	conf.getMySQLVersion()
+}
+
+// builtinDimensions is the dimension table used when no catalog is installed with SetDimensionCatalog
+func builtinDimensions() []Dimension {
+	return []Dimension{
 		{1, "XSmall", 1000, "2GB", 2147483648, 600, 200, 100, 1825361100, 214748364, 107374182},
 		{2, "Small", 2500, "4GB", 4294967296, 2000, 350, 150, 3758096384, 429496729, 107374182},
 		{3, "Medium", 4500, "8GB", 8589934592, 3800, 500, 200, 7516192768, 751619276, 322122547},
@@ -169,22 +351,10 @@ func (conf *Configuration) Init() {
 		{8, "12XLarge", 48000, "192GB", 206158430208, 45000, 2000, 1000, 204010946560, 1610612736, 536870912},
 		{9, "16XLarge", 64000, "256GB", 274877906944, 60000, 3000, 1000, 271656681472, 2147483648, 1073741824},
 		{10, "24XLarge", 96000, "384GB", 412316860416, 90000, 4000, 2000, 408021893120, 2684354560, 1610612736},
-		{DimensionOpen, "Open request by resources", 0, "0GB", 0, 0, 0, 0, 0, 0, 0},
-		{ConnectionDimension, "Open request by Connection", 0, "0GB", 0, 0, 0, 0, 0, 0, 0},
-	}
-
-	conf.LoadType = []LoadType{
-		{1, "Mainly Reads", "Blogs ~10% Writes 90% Reads"},
-		{2, "Light OLTP", "Shops online up to ~40% Writes "},
-		{3, "Heavy OLTP", "Intense analytics, telephony, gaming. 30/70% Reads and Writes"},
-		{4, "Mainly write", "Data load, data ingest. 90% writes"},
 	}
-
-	conf.Connections = []int{50, 100, 200, 500, 1000, 2000}
-	conf.getMySQLVersion()
 }
 
-func (family *Family) Init(DBTypeRequest string) map[string]Family {
+func (family *Family) Init(DBTypeRequest string, ProxyTypeRequest string) map[string]Family {
 	// Group declarations shortened for brevity, functionally identical
 	replicaGroup := map[string]Parameter{
 		"replica_compressed_protocol":   {"replica_compressed_protocol", "configuration", "replication", "1", "1", 0, 1, MySQLVersions{V8_0_46, V11_1_1}},
@@ -193,6 +363,23 @@ func (family *Family) Init(DBTypeRequest string) map[string]Family {
 		"replica_parallel_workers":      {"replica_parallel_workers", "configuration", "replication", "4", "4", 0, 1024, MySQLVersions{V8_0_46, V11_1_1}},
 		"replica_preserve_commit_order": {"replica_preserve_commit_order", "configuration", "replication", "ON", "ON", 0, 1, MySQLVersions{V8_0_46, V11_1_1}},
 	}
+	// asyncReplicaGroup completes the replica group when the node is a plain source/replica
+	asyncReplicaGroup := map[string]Parameter{
+		"log_bin":                                {"log_bin", "configuration", "replication", "binlog", "binlog", 0, 0, MySQLVersions{V8_0_46, V11_1_1}},
+		"binlog_format":                          {"binlog_format", "configuration", "replication", "ROW", "ROW", 0, 0, MySQLVersions{V8_0_46, V11_1_1}},
+		"binlog_row_image":                       {"binlog_row_image", "configuration", "replication", "FULL", "FULL", 0, 0, MySQLVersions{V8_0_46, V11_1_1}},
+		"binlog_expire_logs_seconds":             {"binlog_expire_logs_seconds", "configuration", "replication", "604800", "2592000", 0, 4294967295, MySQLVersions{V8_0_46, V11_1_1}},
+		"max_binlog_size":                        {"max_binlog_size", "configuration", "replication", "1073741824", "1073741824", 4096, 1073741824, MySQLVersions{V8_0_46, V11_1_1}},
+		"binlog_transaction_dependency_tracking": {"binlog_transaction_dependency_tracking", "configuration", "replication", "WRITESET", "COMMIT_ORDER", 0, 0, MySQLVersions{V8_0_46, Version{8, 3, 0}}},
+		"gtid_mode":                              {"gtid_mode", "configuration", "replication", "ON", "OFF", 0, 0, MySQLVersions{V8_0_46, V11_1_1}},
+		"enforce_gtid_consistency":               {"enforce_gtid_consistency", "configuration", "replication", "ON", "OFF", 0, 0, MySQLVersions{V8_0_46, V11_1_1}},
+		"log_replica_updates":                    {"log_replica_updates", "configuration", "replication", "ON", "ON", 0, 1, MySQLVersions{V8_0_46, V11_1_1}},
+		"relay_log":                              {"relay_log", "configuration", "replication", "relay-bin", "relay-bin", 0, 0, MySQLVersions{V8_0_46, V11_1_1}},
+		"relay_log_recovery":                     {"relay_log_recovery", "configuration", "replication", "ON", "OFF", 0, 1, MySQLVersions{V8_0_46, V11_1_1}},
+		"relay_log_purge":                        {"relay_log_purge", "configuration", "replication", "ON", "ON", 0, 1, MySQLVersions{V8_0_46, V11_1_1}},
+		"sync_relay_log":                         {"sync_relay_log", "configuration", "replication", "10000", "10000", 0, 4294967295, MySQLVersions{V8_0_46, V11_1_1}},
+		"replica_pending_jobs_size_max":          {"replica_pending_jobs_size_max", "configuration", "replication", "134217728", "134217728", 1024, 16106127360, MySQLVersions{V8_0_46, V11_1_1}},
+	}
 	connectionGroup := map[string]Parameter{
 		//"binlog_cache_size":      {"binlog_cache_size", "configuration", "connection", "32768", "32768", 32768, 0, MySQLVersions{V8_0_46, Version{10, 1, 0}}},
 		//"binlog_stmt_cache_size": {"binlog_stmt_cache_size", "configuration", "connection", "32768", "32768", 32768, 0, MySQLVersions{V8_0_46, Version{10, 1, 0}}},
@@ -248,7 +435,7 @@ func (family *Family) Init(DBTypeRequest string) map[string]Family {
 		"loose_group_replication_message_cache_size":             {"loose_group_replication_message_cache_size", "configuration", "groupReplication", "1073741824", "1073741824", 134217728, 18446744073709551615, MySQLVersions{V8_0_46, V11_1_1}},
 		"loose_group_replication_communication_max_message_size": {"loose_group_replication_communication_max_message_size", "configuration", "groupReplication", "5097152", "10485760", 0, 1073741824, MySQLVersions{V8_0_46, V11_1_1}},
 		"loose_group_replication_member_expel_timeout":           {"loose_group_replication_member_expel_timeout", "configuration", "groupReplication", "15", "5", 0, 3600, MySQLVersions{V8_0_46, V11_1_1}},
-		//"loose_group_replication_unreachable_majority_timeout":   {"loose_group_replication_unreachable_majority_timeout", "configuration", "groupReplication", "3600", "0", 300, 3600, MySQLVersions{V8_0_46, V11_1_1}},
+		// loose_group_replication_unreachable_majority_timeout is set by the requests with a network, see network.go
 		//"loose_group_replication_poll_spin_loops": {"loose_group_replication_poll_spin_loops", "configuration", "groupReplication", "0", "0", 10000, 40000, MySQLVersions{V8_0_46, V11_1_1}},
 		//"loose_group_replication_compression_threshold":          {"loose_group_replication_compression_threshold", "configuration", "groupReplication", "1000000", "1000000", 129024, 1000000, MySQLVersions{V8_0_46, V11_1_1}},
 		"loose_group_replication_paxos_single_leader":  {"loose_group_replication_paxos_single_leader", "configuration", "groupReplication", "ON", "OFF", 0, 1, MySQLVersions{V8_0_46, V11_1_1}},
@@ -259,9 +446,9 @@ func (family *Family) Init(DBTypeRequest string) map[string]Family {
 	}
 
 	mysqlGroups := map[string]GroupObj{
-		"readinessProbe": {"readinessProbe", map[string]Parameter{"timeoutSeconds": {"timeoutSeconds", "", "readinessProbe", "15", "15", 15, 600, MySQLVersions{}}}},
-		"livenessProbe":  {"livenessProbe", map[string]Parameter{"timeoutSeconds": {"timeoutSeconds", "", "readinessProbe", "5", "5", 5, 600, MySQLVersions{}}}},
-		"resources": {"resources", map[string]Parameter{
+		"readinessProbe": {Name: "readinessProbe", Parameters: map[string]Parameter{"timeoutSeconds": {"timeoutSeconds", "", "readinessProbe", "15", "15", 15, 600, MySQLVersions{}}}},
+		"livenessProbe":  {Name: "livenessProbe", Parameters: map[string]Parameter{"timeoutSeconds": {"timeoutSeconds", "", "readinessProbe", "5", "5", 5, 600, MySQLVersions{}}}},
+		"resources": {Name: "resources", Parameters: map[string]Parameter{
 			"request_memory": {"memory", "request", "resources", "2", "2", 2, 32, MySQLVersions{}},
 			"request_cpu":    {"cpu", "request", "resources", "1000", "1000", 1000, 8500, MySQLVersions{}},
 			"limit_memory":   {"memory", "limit", "resources", "2", "2", 2, 32, MySQLVersions{}},
@@ -270,16 +457,57 @@ func (family *Family) Init(DBTypeRequest string) map[string]Family {
 	}
 
 	haproxyGroups := map[string]GroupObj{
-		"readinessProbe": {"readinessProbe", map[string]Parameter{"timeoutSeconds": {"timeoutSeconds", "", "readinessProbe", "5", "5", 5, 30, MySQLVersions{}}}},
-		"livenessProbe":  {"livenessProbe", map[string]Parameter{"timeoutSeconds": {"timeoutSeconds", "", "readinessProbe", "5", "5", 5, 60, MySQLVersions{}}}},
-		"haproxyConfig": {"haproxy", map[string]Parameter{
+		"readinessProbe": {Name: "readinessProbe", Parameters: map[string]Parameter{"timeoutSeconds": {"timeoutSeconds", "", "readinessProbe", "5", "5", 5, 30, MySQLVersions{}}}},
+		"livenessProbe":  {Name: "livenessProbe", Parameters: map[string]Parameter{"timeoutSeconds": {"timeoutSeconds", "", "readinessProbe", "5", "5", 5, 60, MySQLVersions{}}}},
+		"haproxyConfig": {Name: "haproxy", Parameters: map[string]Parameter{
 			"ha_connection_timeout": {"ha_connection_timeout", "", "haproxyConfig", "5", "1000", 1000, 5000, MySQLVersions{}},
-			"maxconn":               {"maxconn", "", "haproxyConfig", "4048", "2024", 1000, 5000, MySQLVersions{}},
+			"maxconn":               {"maxconn", "", "haproxyConfig", "4048", "2024", 1000, 65535, MySQLVersions{}},
 			"timeout_client":        {"timeout_client", "", "haproxyConfig", "28800", "14400", 1000, 50000, MySQLVersions{}},
 			"timeout_connect":       {"timeout_connect", "", "haproxyConfig", "100500", "100500", 1000, 500000, MySQLVersions{}},
 			"timeout_server":        {"timeout_server", "", "haproxyConfig", "28800", "14400", 1000, 50000, MySQLVersions{}},
 		}},
-		"resources": {"resources", map[string]Parameter{
+		"resources": {Name: "resources", Parameters: map[string]Parameter{
+			"request_memory": {"memory", "request", "resources", "1", "1", 1, 2, MySQLVersions{}},
+			"request_cpu":    {"cpu", "request", "resources", "1000", "1000", 1000, 2000, MySQLVersions{}},
+			"limit_memory":   {"memory", "limit", "resources", "1", "1", 1, 2, MySQLVersions{}},
+			"limit_cpu":      {"cpu", "limit", "resources", "1000", "1000", 1000, 2000, MySQLVersions{}},
+		}},
+	}
+
+	proxysqlGroups := map[string]GroupObj{
+		"readinessProbe": {Name: "readinessProbe", Parameters: map[string]Parameter{"timeoutSeconds": {"timeoutSeconds", "", "readinessProbe", "5", "5", 5, 30, MySQLVersions{}}}},
+		"livenessProbe":  {Name: "livenessProbe", Parameters: map[string]Parameter{"timeoutSeconds": {"timeoutSeconds", "", "readinessProbe", "5", "5", 5, 60, MySQLVersions{}}}},
+		"proxysqlConfig": {Name: "proxysql", Parameters: map[string]Parameter{
+			"mysql-max_connections":         {"mysql-max_connections", "", "proxysqlConfig", "2048", "2048", 1, 1000000, MySQLVersions{}},
+			"mysql-threads":                 {"mysql-threads", "", "proxysqlConfig", "4", "4", 1, 256, MySQLVersions{}},
+			"mysql-query_cache_size_MB":     {"mysql-query_cache_size_MB", "", "proxysqlConfig", "256", "256", 0, 0, MySQLVersions{}},
+			"mysql-free_connections_pct":    {"mysql-free_connections_pct", "", "proxysqlConfig", "10", "10", 0, 100, MySQLVersions{}},
+			"mysql-connect_timeout_server":  {"mysql-connect_timeout_server", "", "proxysqlConfig", "1000", "1000", 1000, 10000, MySQLVersions{}},
+			"mysql-multiplexing":            {"mysql-multiplexing", "", "proxysqlConfig", "true", "true", 0, 1, MySQLVersions{}},
+			"mysql_servers.max_connections": {"mysql_servers.max_connections", "", "proxysqlConfig", "1000", "1000", 1, 65536, MySQLVersions{}},
+		}},
+		"resources": {Name: "resources", Parameters: map[string]Parameter{
+			"request_memory": {"memory", "request", "resources", "1", "1", 1, 2, MySQLVersions{}},
+			"request_cpu":    {"cpu", "request", "resources", "1000", "1000", 1000, 2000, MySQLVersions{}},
+			"limit_memory":   {"memory", "limit", "resources", "1", "1", 1, 2, MySQLVersions{}},
+			"limit_cpu":      {"cpu", "limit", "resources", "1000", "1000", 1000, 2000, MySQLVersions{}},
+		}},
+	}
+
+	// Section holds the mysqlrouter.conf section each option belongs to
+	routerGroups := map[string]GroupObj{
+		"readinessProbe": {Name: "readinessProbe", Parameters: map[string]Parameter{"timeoutSeconds": {"timeoutSeconds", "", "readinessProbe", "5", "5", 5, 30, MySQLVersions{}}}},
+		"livenessProbe":  {Name: "livenessProbe", Parameters: map[string]Parameter{"timeoutSeconds": {"timeoutSeconds", "", "readinessProbe", "5", "5", 5, 60, MySQLVersions{}}}},
+		"routerConfig": {Name: "router", Parameters: map[string]Parameter{
+			"max_total_connections":                       {"max_total_connections", "DEFAULT", "routerConfig", "512", "512", 1, 65535, MySQLVersions{}},
+			"connect_timeout":                             {"connect_timeout", "DEFAULT", "routerConfig", "5", "5", 1, 30, MySQLVersions{}},
+			"client_connect_timeout":                      {"client_connect_timeout", "DEFAULT", "routerConfig", "9", "9", 2, 60, MySQLVersions{}},
+			"routing_rw.max_connections":                  {"max_connections", "routing:bootstrap_rw", "routerConfig", "0", "0", 0, 65535, MySQLVersions{}},
+			"routing_ro.max_connections":                  {"max_connections", "routing:bootstrap_ro", "routerConfig", "0", "0", 0, 65535, MySQLVersions{}},
+			"io.threads":                                  {"threads", "io", "routerConfig", "0", "0", 0, 1024, MySQLVersions{}},
+			"connection_pool.max_idle_server_connections": {"max_idle_server_connections", "connection_pool", "routerConfig", "64", "64", 0, 4294967295, MySQLVersions{}},
+		}},
+		"resources": {Name: "resources", Parameters: map[string]Parameter{
 			"request_memory": {"memory", "request", "resources", "1", "1", 1, 2, MySQLVersions{}},
 			"request_cpu":    {"cpu", "request", "resources", "1000", "1000", 1000, 2000, MySQLVersions{}},
 			"limit_memory":   {"memory", "limit", "resources", "1", "1", 1, 2, MySQLVersions{}},
@@ -288,9 +516,9 @@ func (family *Family) Init(DBTypeRequest string) map[string]Family {
 	}
 
 	pmmGroups := map[string]GroupObj{
-		"readinessProbe": {"readinessProbe", map[string]Parameter{"timeoutSeconds": {"timeoutSeconds", "", "readinessProbe", "5", "5", 5, 30, MySQLVersions{}}}},
-		"livenessProbe":  {"livenessProbe", map[string]Parameter{"timeoutSeconds": {"timeoutSeconds", "", "readinessProbe", "5", "5", 5, 60, MySQLVersions{}}}},
-		"resources": {"resources", map[string]Parameter{
+		"readinessProbe": {Name: "readinessProbe", Parameters: map[string]Parameter{"timeoutSeconds": {"timeoutSeconds", "", "readinessProbe", "5", "5", 5, 30, MySQLVersions{}}}},
+		"livenessProbe":  {Name: "livenessProbe", Parameters: map[string]Parameter{"timeoutSeconds": {"timeoutSeconds", "", "readinessProbe", "5", "5", 5, 60, MySQLVersions{}}}},
+		"resources": {Name: "resources", Parameters: map[string]Parameter{
 			"request_memory": {"memory", "request", "resources", "1", "1", 1, 2, MySQLVersions{}},
 			"request_cpu":    {"cpu", "request", "resources", "1000", "1000", 100, 2000, MySQLVersions{}},
 			"limit_memory":   {"memory", "limit", "resources", "1", "1", 1, 2, MySQLVersions{}},
@@ -298,26 +526,57 @@ func (family *Family) Init(DBTypeRequest string) map[string]Family {
 		}},
 	}
 
-	mysqlGroups["configuration_connection"] = GroupObj{"connections", connectionGroup}
-	mysqlGroups["configuration_server"] = GroupObj{"server", serverGroup}
-	mysqlGroups["configuration_innodb"] = GroupObj{"innodb", innodbGroup}
-	mysqlGroups["configuration_replica"] = GroupObj{"replica", replicaGroup}
+	mysqlGroups["configuration_connection"] = GroupObj{Name: "connections", Parameters: connectionGroup}
+	mysqlGroups["configuration_server"] = GroupObj{Name: "server", Parameters: serverGroup}
+	mysqlGroups["configuration_innodb"] = GroupObj{Name: "innodb", Parameters: innodbGroup}
+	mysqlGroups["configuration_replica"] = GroupObj{Name: "replica", Parameters: replicaGroup}
 
 	if DBTypeRequest == DbTypePXC {
-		mysqlGroups["configuration_galera"] = GroupObj{"galera", wsrepGroup}
+		mysqlGroups["configuration_galera"] = GroupObj{Name: "galera", Parameters: wsrepGroup}
 	}
 
 	if DBTypeRequest == DbTypeGroupReplication {
-		mysqlGroups["configuration_groupReplication"] = GroupObj{"groupReplication", groupReplicationGroup}
+		mysqlGroups["configuration_groupReplication"] = GroupObj{Name: "groupReplication", Parameters: groupReplicationGroup}
+	}
+
+	if DBTypeRequest == DbTypeAsync {
+		for name, parameter := range asyncReplicaGroup {
+			replicaGroup[name] = parameter
+		}
+	}
+
+	proxyFamily := Family{"haproxy", haproxyGroups}
+	switch ProxyTypeRequest {
+	case ProxyTypeProxySQL:
+		proxyFamily = Family{"proxysql", proxysqlGroups}
+	case ProxyTypeRouter:
+		proxyFamily = Family{"router", routerGroups}
 	}
 
 	return map[string]Family{
 		FamilyTypeMysql:   {"mysql", mysqlGroups},
-		FamilyTypeProxy:   {"haproxy", haproxyGroups},
+		FamilyTypeProxy:   proxyFamily,
 		FamilyTypeMonitor: {"pmm", pmmGroups},
 	}
 }
 
+// addParameters adds to the mysql family the parameters only some requests set, by group
+func addParameters(families map[string]Family, byGroup map[string]map[string]Parameter) {
+	mysql, ok := families[FamilyTypeMysql]
+	if !ok {
+		return
+	}
+	for groupName, parameters := range byGroup {
+		group, ok := mysql.Groups[groupName]
+		if !ok {
+			continue
+		}
+		for name, parameter := range parameters {
+			group.Parameters[name] = parameter
+		}
+	}
+}
+
 type ProviderParam struct {
 	Name     string
 	Literal  string
@@ -385,6 +644,45 @@ func (f Family) ParseGroupsHuman() bytes.Buffer {
 	return b
 }
 
+// ParseGroupsMysqld returns the configuration groups of the family as a [mysqld] section
+// that mysqld can load, skipping probes and resources
+func (f Family) ParseGroupsMysqld(padding string) bytes.Buffer {
+	var b bytes.Buffer
+	b.WriteString("[mysqld]\n")
+
+	keys := make([]string, 0, len(f.Groups))
+	for k := range f.Groups {
+		if k == "readinessProbe" || k == "livenessProbe" || k == "resources" {
+			continue
+		}
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		group := f.Groups[key]
+		params := make([]string, 0, len(group.Parameters))
+		for k := range group.Parameters {
+			params = append(params, k)
+		}
+		sort.Strings(params)
+
+		for _, param := range params {
+			fmt.Fprintf(&b, "%s%s = %s\n", padding, param, quoteMysqldValue(group.Parameters[param].Value))
+		}
+	}
+	return b
+}
+
+// quoteMysqldValue wraps values that the option file parser would otherwise split or truncate
+// (e.g. wsrep-provider-options) in double quotes
+func quoteMysqldValue(value string) string {
+	if value == "" || !strings.ContainsAny(value, " \t;#='\"") {
+		return value
+	}
+	return `"` + strings.ReplaceAll(value, `"`, `\"`) + `"`
+}
+
 // ParseFamilyGroup returns the group by name as a byte buffer
 func (f Family) ParseFamilyGroup(groupName string, padding string) (bytes.Buffer, error) {
 	switch groupName {
@@ -392,6 +690,8 @@ func (f Family) ParseFamilyGroup(groupName string, padding string) (bytes.Buffer
 		return f.ParseGroupsHuman(), nil // f.parseGroupHuman("[mysqld]", padding, true), nil
 	case GroupNameHAProxy:
 		return f.ParseGroupsHuman(), nil //f.parseGroupHuman("", padding, true), nil
+	case GroupNameProxySQL, GroupNameRouter:
+		return f.ParseGroupsHuman(), nil
 	case GroupNameProbes:
 		return f.parseProbesHuman(padding), nil
 	case GroupNameResources:
@@ -431,6 +731,14 @@ func (f Family) parseParamsHuman(b *bytes.Buffer, group GroupObj, padding string
 
 	// 2. Iterate in alphabetical order
 	for _, key := range keys {
+		if group.IsPinned(key) {
+			fmt.Fprintf(b, "%s%s = %s  # pinned\n", padding, key, group.Parameters[key].Value)
+			continue
+		}
+		if trace := group.Traces[key]; trace != nil && len(trace.PinnedOptions) > 0 {
+			fmt.Fprintf(b, "%s%s = %s  # pinned %s\n", padding, key, group.Parameters[key].Value, strings.Join(trace.PinnedOptions, ", "))
+			continue
+		}
 		fmt.Fprintf(b, "%s%s = %s\n", padding, key, group.Parameters[key].Value)
 	}
 
@@ -37,6 +37,7 @@ const (
 	LoadTypeSomeWrites       = 2 // ~80% reads, ~20% writes (e-commerce, light OLTP)
 	LoadTypeEqualReadsWrites = 3 // ~50/50 (mixed analytics, heavy OLTP)
 	LoadTypeHeavyWrites      = 4 // write-dominated (log ingestion, event streams, high-frequency inserts)
+	LoadTypeAnalytics        = 5 // reporting replicas, BI: few connections running large scans, joins and sorts
 
 	// ---------------------------------------------------------------------------
 	// Special dimension IDs — sentinel values outside the normal dimension table.
@@ -55,13 +56,15 @@ const (
 	// ---------------------------------------------------------------------------
 
 	FamilyTypeMysql   = "mysql"   // MySQL container configuration family
-	FamilyTypeProxy   = "proxy"   // HAProxy sidecar configuration family
+	FamilyTypeProxy   = "proxy"   // proxy sidecar configuration family (HAProxy, ProxySQL or MySQL Router)
 	FamilyTypeMonitor = "monitor" // PMM monitoring sidecar configuration family
 
-	GroupNameMySQLd    = "mysqld"        // MySQL daemon parameters (my.cnf variables)
-	GroupNameProbes    = "probes"        // Kubernetes liveness / readiness probe timings
-	GroupNameResources = "resources"     // Kubernetes CPU and memory requests/limits
-	GroupNameHAProxy   = "haproxyConfig" // HAProxy-specific settings (maxconn, timeouts)
+	GroupNameMySQLd    = "mysqld"         // MySQL daemon parameters (my.cnf variables)
+	GroupNameProbes    = "probes"         // Kubernetes liveness / readiness probe timings
+	GroupNameResources = "resources"      // Kubernetes CPU and memory requests/limits
+	GroupNameHAProxy   = "haproxyConfig"  // HAProxy-specific settings (maxconn, timeouts)
+	GroupNameProxySQL  = "proxysqlConfig" // ProxySQL-specific settings (mysql-* variables, connection pool)
+	GroupNameRouter    = "routerConfig"   // MySQL Router settings (connection limits, timeouts, io threads)
 
 	// ---------------------------------------------------------------------------
 	// Database type strings — passed in the dbtype field of the request.
@@ -69,13 +72,69 @@ const (
 
 	DbTypePXC              = "pxc"               // Percona XtraDB Cluster (Galera-based synchronous replication)
 	DbTypeGroupReplication = "group_replication" // MySQL Group Replication (InnoDB Cluster)
+	DbTypeAsync            = "async"             // Percona Server source/replica asynchronous replication
+
+	// ---------------------------------------------------------------------------
+	// Proxy type strings — passed in the proxytype field of the request.
+	// An empty proxytype keeps the HAProxy family.
+	// ---------------------------------------------------------------------------
+
+	ProxyTypeHAProxy  = "haproxy"  // HAProxy TCP load balancer
+	ProxyTypeProxySQL = "proxysql" // ProxySQL query-aware proxy with connection pooling
+	ProxyTypeRouter   = "router"   // MySQL Router, group_replication (InnoDB Cluster) only
 
 	// ---------------------------------------------------------------------------
 	// Output format strings — passed in the output field of the request.
 	// ---------------------------------------------------------------------------
 
-	ResultOutputFormatJson  = "json"  // structured JSON — suitable for automation and Operators
-	ResultOutputFormatHuman = "human" // INI-style flat text — suitable for my.cnf or manual review
+	ResultOutputFormatJson       = "json"        // structured JSON — suitable for automation and Operators
+	ResultOutputFormatHuman      = "human"       // INI-style flat text — suitable for manual review
+	ResultOutputFormatMyCnf      = "mycnf"       // [mysqld] option file — loadable by mysqld as is
+	ResultOutputFormatPXCCR      = "pxc_cr"      // PerconaXtraDBCluster custom resource YAML — ready for kubectl apply
+	ResultOutputFormatPSCR       = "ps_cr"       // PerconaServerMySQL (group replication) custom resource YAML
+	ResultOutputFormatHAProxyCfg = "haproxy_cfg" // haproxy.cfg global/defaults/backend stanzas for the proxy family
+
+	// ---------------------------------------------------------------------------
+	// Custom resource rendering defaults — used by the operator CR output formats.
+	// Images are tagged by MySQL major.minor taken from the request.
+	// ---------------------------------------------------------------------------
+
+	CRDefaultName    = "cluster1" // metadata.name of the generated custom resource
+	CRPmmServerHost  = "monitoring-service"
+	CRDefaultStorage = "6G" // data volume requested by each database pod
+	PXCCRApiVersion  = "pxc.percona.com/v1"
+	PXCCRKind        = "PerconaXtraDBCluster"
+	PXCCRVersion     = "1.15.0"
+	PXCCRImage       = "percona/percona-xtradb-cluster"
+	PSCRApiVersion   = "ps.percona.com/v1alpha1"
+	PSCRKind         = "PerconaServerMySQL"
+	PSCRVersion      = "0.12.0"
+	PSCRImage        = "percona/percona-server"
+	PSCRClusterType  = "group-replication" // spec.mysql.clusterType for group replication clusters
+	CRHAProxyImage   = "percona/haproxy:2.8.5"
+	CRProxySQLImage  = "percona/proxysql2:2.5.5"
+	CRRouterImage    = "percona/percona-mysql-router"
+	CRPmmClientImage = "percona/pmm-client:2.42.0"
+
+	// DefaultClusterNodes is the number of database members assumed when the request does not
+	// set nodes, and the number of proxy pods.
+	DefaultClusterNodes = 3
+
+	// RouterPooledConnectionMemory is the memory in bytes assumed for every idle server
+	// connection kept in the MySQL Router connection pool.
+	RouterPooledConnectionMemory = 1048576 // 1 MiB
+
+	// RouterClientConnectionMemory is the memory in bytes assumed for every client connection
+	// MySQL Router routes (client and server socket buffers).
+	RouterClientConnectionMemory = 262144 // 256 KiB
+
+	// RouterConnectionsPerCore is the number of client connections one MySQL Router io thread,
+	// one per proxy core, is assumed to carry.
+	RouterConnectionsPerCore = 2000
+
+	// AdminConnections is the number of max_connections slots kept for administrative
+	// use on top of the requested connections.
+	AdminConnections = 2
 
 	// ---------------------------------------------------------------------------
 	// InnoDB buffer pool sizing fractions
@@ -91,6 +150,11 @@ const (
 	// headroom to avoid OOM kills.
 	InnoDBPctValueGR = 0.70
 
+	// InnoDBPctValueAsync is the same ceiling for asynchronous replication. Each node
+	// only carries its own workload plus the binlog/relay-log caches, there is no
+	// GCache or certification cache to reserve memory for.
+	InnoDBPctValueAsync = 0.85
+
 	// GroupRepGCSCacheMemStructureCost is a fixed 50 MiB reserved for the Group
 	// Replication message-cache data structure itself. It is deducted from MySQL
 	// memory before the buffer pool is sized, on top of the per-connection GCS cost.
@@ -114,6 +178,11 @@ const (
 	// for certification and message caches.
 	MinLimitGR = 0.40
 
+	// MinLimitAsync: same floor for asynchronous replication — ~53% of total dimension
+	// memory. Derived from PXC: the floor keeps the same proportion of the buffer pool
+	// ceiling (0.50 / 0.80), applied to the higher async ceiling.
+	MinLimitAsync = MinLimitPXC * InnoDBPctValueAsync / InnoDBPctValuePXC
+
 	// MemoryFreeMinimumLimit reserves 2% of total MySQL memory, never allocated to
 	// any structure, as a safety margin for allocator overhead and OS paging.
 	MemoryFreeMinimumLimit = 0.02
@@ -159,6 +228,7 @@ const (
 	CpuConncetionMillFactorReadWriteLight = 1.2 // light OLTP: binlog + replication add up
 	CpuConncetionMillFactorReadWriteEqual = 1.6 // equal reads/writes: lock contention overhead
 	CpuConncetionMillFactorReadWriteHeavy = 2   // heavy writes: maximum CPU demand per connection
+	CpuConncetionMillFactorAnalytics      = 10  // analytics: every query keeps cores busy with scans and sorts
 
 	// ---------------------------------------------------------------------------
 	// Connection thresholds
@@ -176,4 +246,27 @@ const (
 	// MaxAutoConnections caps the auto-connection search loop (connections = 0) to
 	// prevent an unbounded loop on very large instances.
 	MaxAutoConnections = 500000
+
+	// ---------------------------------------------------------------------------
+	// Kubernetes providers — accepted in the provider field of the request.
+	// Each one maps to a profile of kube/system reservations and DaemonSets
+	// deducted from open (999) and instance type dimensions.
+	// ---------------------------------------------------------------------------
+
+	ProviderEKS       = "eks"
+	ProviderGKE       = "gke"
+	ProviderAKS       = "aks"
+	ProviderOpenShift = "openshift"
+	ProviderBareMetal = "bare-metal"
+
+	// EvictionHardMemory is the memory.available threshold (100Mi) the kubelets keep free
+	EvictionHardMemory = 104857600
+
+	// Deduction names as reported in ProviderDeductions
+	DeductionKubeReserved = "kube-reserved"
+	DeductionEviction     = "eviction-hard"
+	DeductionDaemonSets   = "daemonsets"
+
+	mib = 1048576
+	gib = 1073741824
 )
@@ -0,0 +1,68 @@
+package mysqloperatorcalculator
+
+import (
+	"strconv"
+)
+
+// Per connection buffers and temporary table size of the analytics load type
+const (
+	analyticsJoinBuffer    = 8388608  // 8MiB, joins without an index on large tables
+	analyticsSortBuffer    = 8388608  // 8MiB, ORDER BY and GROUP BY on large results
+	analyticsReadRndBuffer = 4194304  // 4MiB, sorted row reads of large results
+	analyticsTmpTableSize  = 67108864 // 64MiB, in memory temporary table before it goes to disk
+	// analyticsTmpTableShare is the share of tmp_table_size each running query is expected to use
+	analyticsTmpTableShare = 0.5
+	// analyticsOldBlocksPct is the LRU share for the pages read by scans (default 37)
+	analyticsOldBlocksPct = 20
+	// analyticsBufferPoolShare is the part of the dbtype buffer pool share analytics takes, the sorts and
+	// temporary tables above tmp_table_size spill to files read back through the page cache
+	analyticsBufferPoolShare = 0.90
+)
+
+func analyticsParameters() map[string]map[string]Parameter {
+	return map[string]map[string]Parameter{
+		"configuration_server": {
+			"temptable_max_ram": {"temptable_max_ram", "configuration", "server", "1073741824", "1073741824", 2097152, 0, MySQLVersions{V8_0_46, V11_1_1}},
+		},
+		"configuration_innodb": {
+			"innodb_old_blocks_pct": {"innodb_old_blocks_pct", "configuration", "innodb", "37", "37", 5, 95, MySQLVersions{V8_0_46, V11_1_1}},
+		},
+	}
+}
+
+// getAnalyticsConnectionBuffers sets the per connection buffers and the temporary table size of the analytics load type
+func (c *Configurator) getAnalyticsConnectionBuffers(group GroupObj) {
+	fixed := func(value int64) func(Parameter) Parameter {
+		return func(p Parameter) Parameter {
+			p.Value = strconv.FormatInt(value, 10)
+			return p
+		}
+	}
+	c.traced(group, "join_buffer_size", fixed(analyticsJoinBuffer), "per connection buffer fixed for analytics", traceLoadID)
+	c.traced(group, "read_rnd_buffer_size", fixed(analyticsReadRndBuffer), "per connection buffer fixed for analytics", traceLoadID)
+	c.traced(group, "sort_buffer_size", fixed(analyticsSortBuffer), "per connection buffer fixed for analytics", traceLoadID)
+	c.traced(group, "tmp_table_size", fixed(analyticsTmpTableSize), "in memory temporary table fixed for analytics", traceLoadID)
+	c.traced(group, "max_heap_table_size", fixed(analyticsTmpTableSize), "same as tmp_table_size for analytics", traceLoadID)
+}
+
+// paramTemptableMaxRAM caps the temporary tables in memory to what the connection buffers accounted for them,
+// never below one tmp_table_size
+func (c *Configurator) paramTemptableMaxRAM(parameter Parameter) Parameter {
+	budget := c.reference.tmpTableMemTot
+	tmpTableSize, _ := strconv.ParseInt(c.families[FamilyTypeMysql].Groups["configuration_connection"].Parameters["tmp_table_size"].Value, 10, 64)
+	if budget < tmpTableSize {
+		budget = tmpTableSize
+		c.branch("one tmp_table_size")
+	}
+	if budget < int64(parameter.Min) {
+		budget = int64(parameter.Min)
+		c.clamped("min", int(parameter.Min))
+	}
+	parameter.Value = strconv.FormatInt(budget, 10)
+	return parameter
+}
+
+func (c *Configurator) paramInnoDBOldBlocksPct(parameter Parameter) Parameter {
+	parameter.Value = strconv.Itoa(analyticsOldBlocksPct)
+	return parameter
+}
@@ -0,0 +1,92 @@
+package mysqloperatorcalculator
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestBufferPoolPct_Analytics(t *testing.T) {
+	for _, dbtype := range []string{DbTypePXC, DbTypeGroupReplication, DbTypeAsync} {
+		reads := newTestConfigurator(LoadTypeMostlyReads, dbtype, 10, 4000, 8*testGB)
+		if got := reads.bufferPoolPct(); got != bufferPoolPctByDBType(dbtype) {
+			t.Errorf("%s mainly reads bufferPoolPct() = %.3f, want %.3f", dbtype, got, bufferPoolPctByDBType(dbtype))
+		}
+		analytics := newTestConfigurator(LoadTypeAnalytics, dbtype, 10, 4000, 8*testGB)
+		if got, want := analytics.bufferPoolPct(), bufferPoolPctByDBType(dbtype)*analyticsBufferPoolShare; got != want {
+			t.Errorf("%s analytics bufferPoolPct() = %.3f, want %.3f", dbtype, got, want)
+		}
+	}
+}
+
+func TestGetRedologfilesNumber_Analytics(t *testing.T) {
+	cases := []struct {
+		loadID int
+		want   string
+	}{
+		{LoadTypeMostlyReads, "3"},
+		{LoadTypeAnalytics, "3"},
+		{LoadTypeEqualReadsWrites, "5"},
+	}
+	for _, tc := range cases {
+		c := newTestConfigurator(tc.loadID, DbTypePXC, 10, 4000, 8*testGB)
+		c.reference.innodbRedoLogDim = 1500 * testMB
+		if got := c.getRedologfilesNumber(Parameter{}).Value; got != tc.want {
+			t.Errorf("loadID=%d getRedologfilesNumber: got %s, want %s", tc.loadID, got, tc.want)
+		}
+	}
+}
+
+func TestAnalytics_Calculate(t *testing.T) {
+	err, msg, families := runCalculate(makeRequest(DbTypePXC, 4, LoadTypeAnalytics, 100))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if msg.MType != OkI {
+		t.Errorf("100 analytics connections on a Large pod must fit, got %d", msg.MType)
+	}
+	mysql := families[FamilyTypeMysql]
+	value := func(group, name string) int64 {
+		t.Helper()
+		p, ok := mysql.Groups[group].Parameters[name]
+		if !ok {
+			t.Fatalf("%s missing", name)
+		}
+		v, _ := strconv.ParseInt(p.Value, 10, 64)
+		return v
+	}
+	if value("configuration_connection", "join_buffer_size") != 8388608 || value("configuration_connection", "sort_buffer_size") != 8388608 {
+		t.Error("analytics join and sort buffers must be 8MiB")
+	}
+	// 18 of the 100 connections run a query at loadFactor 0.18, each with half a tmp_table_size in memory
+	if tmp, ram := value("configuration_connection", "tmp_table_size"), value("configuration_server", "temptable_max_ram"); tmp != 67108864 || ram != 18*tmp/2 {
+		t.Errorf("temptable_max_ram = %d, want the temporary tables of the connection buffers %d", ram, 18*tmp/2)
+	}
+	if value("configuration_innodb", "innodb_parallel_read_threads") != 10 || value("configuration_innodb", "innodb_old_blocks_pct") != 20 {
+		t.Error("analytics must scan with two threads per mysql core and a smaller LRU old sublist")
+	}
+
+	// Few, heavy connections: the auto discovery stops far below the mainly reads one
+	_, _, reads := runCalculate(makeRequest(DbTypePXC, 4, LoadTypeMostlyReads, 0))
+	_, _, analytics := runCalculate(makeRequest(DbTypePXC, 4, LoadTypeAnalytics, 0))
+	readsConn, _ := strconv.Atoi(reads[FamilyTypeMysql].Groups["configuration_server"].Parameters["max_connections"].Value)
+	analyticsConn, _ := strconv.Atoi(analytics[FamilyTypeMysql].Groups["configuration_server"].Parameters["max_connections"].Value)
+	if analyticsConn == 0 || analyticsConn*5 > readsConn {
+		t.Errorf("analytics max_connections %d must be a small fraction of the mainly reads %d", analyticsConn, readsConn)
+	}
+	if _, ok := reads[FamilyTypeMysql].Groups["configuration_server"].Parameters["temptable_max_ram"]; ok {
+		t.Error("temptable_max_ram is only set for analytics")
+	}
+
+	var conf Configuration
+	conf.Init()
+	if load := conf.GetLoadByID(LoadTypeAnalytics); load.Name != "Analytics" {
+		t.Errorf("analytics must be listed in the supported load types, got %+v", load)
+	}
+
+	ratio := 0.1
+	req := makeRequest(DbTypePXC, 4, LoadTypeAnalytics, 100)
+	req.WriteRatio = &ratio
+	if err, _, _ := runCalculate(req); err == nil {
+		t.Error("writeratio must be rejected with the analytics load type")
+	}
+}
@@ -0,0 +1,320 @@
+package mysqloperatorcalculator
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"code.cloudfoundry.org/bytefmt"
+)
+
+// Audit finding kinds
+const (
+	FindingOverMax   = "over-max"
+	FindingBelowMin  = "below-min"
+	FindingInvalid   = "invalid"
+	FindingVersion   = "version"
+	FindingDiverges  = "diverges"
+	FindingUnmanaged = "unmanaged"
+)
+
+// myCnfProviderOptions is the option file name of the wsrep-provider-options parameter
+const myCnfProviderOptions = "wsrep_provider_options"
+
+// AuditRequest is the body of the audit endpoint, the request describing the pod plus the option file to check
+type AuditRequest struct {
+	ConfigurationRequest
+	MyCnf string `json:"mycnf"`
+}
+
+type AuditFinding struct {
+	Parameter   string `json:"parameter"`
+	Value       string `json:"value"`
+	Recommended string `json:"recommended,omitempty"`
+	Kind        string `json:"kind"`
+	Detail      string `json:"detail"`
+}
+
+// AuditReport Message carries the verdict (Ok, Close to limit or Overutilizing) and the resource breakdown
+type AuditReport struct {
+	Message     ResponseMessage `json:"message"`
+	Connections int             `json:"connections"`
+	Findings    []AuditFinding  `json:"findings"`
+}
+
+// GetAudit checks the [mysqld] section of myCnf against the incoming request dimension, load and version.
+// Connections not set in the request are taken from max_connections
+func (moc *MysqlOperatorCalculator) GetAudit(myCnf io.Reader) (error, AuditReport) {
+	var report AuditReport
+	request := moc.IncomingRequest
+	defer func() { moc.IncomingRequest = request }()
+
+	sections, err := ParseMyCnf(myCnf)
+	if err != nil {
+		return err, report
+	}
+	mysqld, ok := sections["mysqld"]
+	if !ok {
+		return errors.New("the option file has no [mysqld] section"), report
+	}
+
+	if err := moc.validateRequest(); err != nil {
+		return err, report
+	}
+	if request.Dimension.Id == 998 {
+		return errors.New("an audit needs the pod resources, dimension 998 is not supported"), report
+	}
+
+	audited := request
+	if audited.Connections == 0 {
+		maxConnections, err := strconv.Atoi(mysqld["max_connections"])
+		if err != nil || maxConnections <= AdminConnections {
+			return errors.New("connections missing: set connections in the request or max_connections in the option file"), report
+		}
+		audited.Connections = maxConnections - AdminConnections
+	}
+
+	families := requestFamilies(audited)
+	overrides, findings := auditMyCnfValues(mysqld, families, audited)
+
+	// The recommendation for the same pod and connections, the audited values pinned over it give the verdict
+	_, recommended, err := moc.auditCalculate(audited)
+	if err != nil {
+		return err, report
+	}
+	audited.Overrides = overrides
+	message, _, err := moc.auditCalculate(audited)
+	if err != nil {
+		return err, report
+	}
+
+	findings = append(findings, auditDivergences(overrides, recommended)...)
+	sort.SliceStable(findings, func(i, j int) bool { return findings[i].Parameter < findings[j].Parameter })
+
+	report.Message = message
+	report.Connections = audited.Connections
+	report.Findings = findings
+	return nil, report
+}
+
+// auditCalculate runs the calculation for the request, an overutilizing pod is the verdict of the audit, not an error
+func (moc *MysqlOperatorCalculator) auditCalculate(request ConfigurationRequest) (ResponseMessage, map[string]Family, error) {
+	moc.IncomingRequest = request
+	err, message, families := moc.getCalculateInt()
+	if err != nil && message.MType != OverutilizingI {
+		return message, families, err
+	}
+	return message, families, nil
+}
+
+// auditMyCnfValues maps the option file onto the mysql family, the values that can be used in the accounting
+// are returned as overrides, the others (unmanaged, invalid, above the maximum) only as findings
+func auditMyCnfValues(mysqld map[string]string, families map[string]Family, request ConfigurationRequest) (map[string]string, []AuditFinding) {
+	overrides := make(map[string]string)
+	var findings []AuditFinding
+
+	for option, value := range mysqld {
+		name := myCnfName(option)
+		if name == myCnfProviderOptions && request.DBType == DbTypePXC {
+			for key, v := range splitProviderOptions(value) {
+				if key != "gcache.size" {
+					continue
+				}
+				if size, ok := myCnfNumber(v); ok {
+					overrides[key] = strconv.FormatUint(size, 10)
+				} else {
+					findings = append(findings, AuditFinding{Parameter: key, Value: v, Kind: FindingInvalid, Detail: "not a size"})
+				}
+			}
+			continue
+		}
+
+		parameter, ok := lookupMyCnfParameter(families, name)
+		if !ok {
+			findings = append(findings, AuditFinding{Parameter: option, Value: value, Kind: FindingUnmanaged,
+				Detail: fmt.Sprintf("not managed by the calculator for DB Type %s, not audited", request.DBType)})
+			continue
+		}
+		if !parameterInVersion(parameter, request.Mysqlversion) {
+			findings = append(findings, AuditFinding{Parameter: parameter.Name, Value: value, Kind: FindingVersion,
+				Detail: fmt.Sprintf("not valid in MySQL %d.%d.%d, only from %d.%d.%d to %d.%d.%d",
+					request.Mysqlversion.Major, request.Mysqlversion.Minor, request.Mysqlversion.Patch,
+					parameter.Mysqlversions.Min.Major, parameter.Mysqlversions.Min.Minor, parameter.Mysqlversions.Min.Patch,
+					parameter.Mysqlversions.Max.Major, parameter.Mysqlversions.Max.Minor, parameter.Mysqlversions.Max.Patch)})
+			continue
+		}
+
+		if _, err := strconv.ParseInt(parameter.Default, 10, 64); err != nil {
+			overrides[parameter.Name] = value
+			continue
+		}
+		v, ok := myCnfNumber(value)
+		if !ok && myCnfBool(value) != "" {
+			// numeric switches (innodb_adaptive_hash_index) are also set with ON/OFF
+			overrides[parameter.Name] = value
+			continue
+		} else if !ok {
+			findings = append(findings, AuditFinding{Parameter: parameter.Name, Value: value, Kind: FindingInvalid, Detail: "not a number"})
+			continue
+		}
+		if kind, detail := parameterBounds(parameter, v); kind != "" {
+			findings = append(findings, AuditFinding{Parameter: parameter.Name, Value: value, Kind: kind, Detail: detail})
+			// mysqld refuses a value above the maximum, the calculated one is accounted instead
+			if kind == FindingOverMax {
+				continue
+			}
+		}
+		overrides[parameter.Name] = strconv.FormatUint(v, 10)
+	}
+	return overrides, findings
+}
+
+// auditDivergences compares the audited values with the recommended ones, nothing is compared when the
+// recommendation could not be calculated
+func auditDivergences(overrides map[string]string, recommended map[string]Family) []AuditFinding {
+	var findings []AuditFinding
+	var providerOptions map[string]string
+	providerParams := providerDefaults()
+
+	for name, value := range overrides {
+		if _, ok := providerParams[name]; ok {
+			if providerOptions == nil {
+				if p, ok := lookupOverridable(recommended, "wsrep-provider-options"); ok {
+					providerOptions = splitProviderOptions(p.Value)
+				}
+			}
+			if r, ok := providerOptions[name]; ok && r != value {
+				findings = append(findings, AuditFinding{Parameter: name, Value: value, Recommended: r, Kind: FindingDiverges, Detail: "differs from the recommendation"})
+			}
+			continue
+		}
+		if p, ok := lookupOverridable(recommended, name); ok && !sameMyCnfValue(value, p.Value) {
+			findings = append(findings, AuditFinding{Parameter: name, Value: value, Recommended: p.Value, Kind: FindingDiverges, Detail: "differs from the recommendation"})
+		}
+	}
+	return findings
+}
+
+// providerDefaults returns the wsrep provider options with their predefined values
+func providerDefaults() map[string]ProviderParam {
+	var pP ProviderParam
+	return pP.Init()
+}
+
+// parameterBounds returns the finding kind and detail when v is outside the parameter Min/Max, Max 0 means no upper bound
+func parameterBounds(parameter Parameter, v uint64) (string, string) {
+	if v < parameter.Min {
+		return FindingBelowMin, fmt.Sprintf("%s=%d is below the minimum %d", parameter.Name, v, parameter.Min)
+	}
+	if parameter.Max > 0 && v > parameter.Max {
+		return FindingOverMax, fmt.Sprintf("%s=%d is above the maximum %d", parameter.Name, v, parameter.Max)
+	}
+	return "", ""
+}
+
+// myCnfName returns the option as the calculator names it, mysqld accepts dashes for underscores
+func myCnfName(option string) string {
+	return strings.ReplaceAll(strings.ToLower(option), "-", "_")
+}
+
+// lookupMyCnfParameter finds the option with or without the loose_ prefix
+func lookupMyCnfParameter(families map[string]Family, name string) (Parameter, bool) {
+	if parameter, ok := lookupOverridable(families, name); ok {
+		return parameter, true
+	}
+	if parameter, ok := lookupOverridable(families, "loose_"+name); ok {
+		return parameter, true
+	}
+	return lookupOverridable(families, strings.TrimPrefix(name, "loose_"))
+}
+
+// myCnfNumber converts an option value to a number, sizes may use the K, M and G suffixes
+func myCnfNumber(value string) (uint64, bool) {
+	if v, err := strconv.ParseUint(value, 10, 64); err == nil {
+		return v, true
+	}
+	v, err := bytefmt.ToBytes(value)
+	return v, err == nil
+}
+
+func sameMyCnfValue(a string, b string) bool {
+	if strings.EqualFold(a, b) {
+		return true
+	}
+	return myCnfBool(a) != "" && myCnfBool(a) == myCnfBool(b)
+}
+
+// myCnfBool returns on or off for the spellings mysqld accepts for a boolean, empty otherwise
+func myCnfBool(value string) string {
+	switch strings.ToLower(value) {
+	case "on", "true", "1":
+		return "on"
+	case "off", "false", "0":
+		return "off"
+	default:
+		return ""
+	}
+}
+
+// splitProviderOptions returns the options of a wsrep_provider_options string by name
+func splitProviderOptions(value string) map[string]string {
+	options := make(map[string]string)
+	for _, option := range strings.Split(value, ";") {
+		key, v, ok := strings.Cut(option, "=")
+		if ok {
+			options[strings.TrimSpace(key)] = strings.TrimSpace(v)
+		}
+	}
+	return options
+}
+
+func (moc *MysqlOperatorCalculator) GetAuditJSONOutput(report AuditReport, request ConfigurationRequest) (bytes.Buffer, error) {
+	var b bytes.Buffer
+
+	responsePayload := struct {
+		Audit struct {
+			Message     ResponseMessage      `json:"message"`
+			Incoming    ConfigurationRequest `json:"incoming"`
+			Connections int                  `json:"connections"`
+			Findings    []AuditFinding       `json:"findings"`
+		} `json:"audit"`
+	}{}
+
+	responsePayload.Audit.Message = report.Message
+	responsePayload.Audit.Incoming = request
+	responsePayload.Audit.Connections = report.Connections
+	responsePayload.Audit.Findings = report.Findings
+
+	output, err := json.MarshalIndent(responsePayload, "", "  ")
+	if err != nil {
+		return b, err
+	}
+
+	b.Write(output)
+	return b, nil
+}
+
+func (moc *MysqlOperatorCalculator) GetAuditHumanOutput(report AuditReport, request ConfigurationRequest) (bytes.Buffer, error) {
+	var b bytes.Buffer
+
+	b.WriteString("[message]\n")
+	b.WriteString("name = " + report.Message.MName + "\n")
+	b.WriteString("type = " + strconv.Itoa(report.Message.MType) + "\n")
+	b.WriteString("text = " + report.Message.MText + "\n")
+	fmt.Fprintf(&b, "connections = %d\n", report.Connections)
+
+	b.WriteString("\n[findings]\n")
+	for _, f := range report.Findings {
+		fmt.Fprintf(&b, "%s = %s  # %s: %s", f.Parameter, f.Value, f.Kind, f.Detail)
+		if f.Recommended != "" {
+			fmt.Fprintf(&b, ", recommended %s", f.Recommended)
+		}
+		b.WriteString("\n")
+	}
+	return b, nil
+}
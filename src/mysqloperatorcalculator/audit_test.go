@@ -0,0 +1,132 @@
+package mysqloperatorcalculator
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func runAudit(req ConfigurationRequest, myCnf string) (error, AuditReport) {
+	var conf Configuration
+	conf.Init()
+	var moc MysqlOperatorCalculator
+	moc.Init(req, conf)
+	return moc.GetAudit(strings.NewReader(myCnf))
+}
+
+// The option file the calculator generates must audit clean for the same request.
+func TestAudit_GeneratedMyCnf(t *testing.T) {
+	for _, dbtype := range []string{DbTypePXC, DbTypeGroupReplication, DbTypeAsync} {
+		t.Run(dbtype, func(t *testing.T) {
+			req := makeRequest(dbtype, 4, LoadTypeSomeWrites, 200)
+			err, msg, families := runCalculate(req)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var moc MysqlOperatorCalculator
+			b, _ := moc.GetMyCnfOutput(msg, req, families)
+
+			// connections come from max_connections
+			req.Connections = 0
+			err, report := runAudit(req, b.String())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if report.Connections != 200 {
+				t.Errorf("connections = %d, want 200 from max_connections", report.Connections)
+			}
+			if report.Message.MType != msg.MType {
+				t.Errorf("verdict %d, want %d as the calculation", report.Message.MType, msg.MType)
+			}
+			if report.Message.Breakdown == nil || report.Message.Breakdown.Memory.Mysql.BufferPool != msg.Breakdown.Memory.Mysql.BufferPool {
+				t.Error("breakdown must account for the audited buffer pool")
+			}
+			for _, f := range report.Findings {
+				t.Errorf("unexpected finding %+v", f)
+			}
+		})
+	}
+}
+
+func TestAudit_Findings(t *testing.T) {
+	myCnf := `[mysqld]
+datadir = /var/lib/mysql
+max_connections = 202
+innodb-buffer-pool-size = 64G
+tmp_table_size = 1K
+innodb_flush_log_at_trx_commit = 5
+wsrep_provider_options = "gcache.size=1G;gcs.fc_limit=16"
+`
+	err, report := runAudit(makeRequest(DbTypePXC, 4, LoadTypeSomeWrites, 0), myCnf)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.Message.MType != OverutilizingI {
+		t.Errorf("a 64G buffer pool on a 16G pod must be overutilizing, got %d", report.Message.MType)
+	}
+
+	kinds := make(map[string][]string)
+	for _, f := range report.Findings {
+		kinds[f.Parameter] = append(kinds[f.Parameter], f.Kind)
+	}
+	for parameter, kind := range map[string]string{
+		"datadir":                        FindingUnmanaged,
+		"tmp_table_size":                 FindingBelowMin,
+		"innodb_flush_log_at_trx_commit": FindingOverMax,
+		"innodb_buffer_pool_size":        FindingDiverges,
+		"gcache.size":                    FindingDiverges,
+	} {
+		if !strings.Contains(strings.Join(kinds[parameter], ","), kind) {
+			t.Errorf("%s: expected a %s finding, got %v", parameter, kind, kinds[parameter])
+		}
+	}
+
+	// a value above the maximum is reported, not pinned over the calculation
+	overrides, _ := auditMyCnfValues(map[string]string{"innodb_flush_log_at_trx_commit": "5", "tmp_table_size": "1K"},
+		requestFamilies(makeRequest(DbTypePXC, 4, LoadTypeSomeWrites, 0)), makeRequest(DbTypePXC, 4, LoadTypeSomeWrites, 0))
+	if _, ok := overrides["innodb_flush_log_at_trx_commit"]; ok || overrides["tmp_table_size"] != "1024" {
+		t.Errorf("only the values within the maximum are pinned, got %v", overrides)
+	}
+
+	var moc MysqlOperatorCalculator
+	b, err := moc.GetAuditJSONOutput(report, makeRequest(DbTypePXC, 4, LoadTypeSomeWrites, 0))
+	if err != nil || !strings.Contains(b.String(), `"kind": "over-max"`) {
+		t.Errorf("JSON output must carry the findings, err=%v", err)
+	}
+	b, _ = moc.GetAuditHumanOutput(report, makeRequest(DbTypePXC, 4, LoadTypeSomeWrites, 0))
+	if !bytes.Contains(b.Bytes(), []byte("[findings]\n")) {
+		t.Error("human output must list the findings")
+	}
+}
+
+func TestAudit_VersionFinding(t *testing.T) {
+	req := makeRequest(DbTypeGroupReplication, 4, LoadTypeSomeWrites, 100)
+	req.Mysqlversion = Version{Major: 8, Minor: 0, Patch: 44}
+	err, report := runAudit(req, "[mysqld]\ntmp_table_size = 64M\n")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(report.Findings) != 1 || report.Findings[0].Kind != FindingVersion {
+		t.Errorf("expected one version finding, got %+v", report.Findings)
+	}
+}
+
+func TestAudit_Errors(t *testing.T) {
+	cases := map[string]struct {
+		req   ConfigurationRequest
+		myCnf string
+	}{
+		"no mysqld section":     {makeRequest(DbTypePXC, 4, LoadTypeSomeWrites, 100), "[client]\nport = 3306\n"},
+		"no connections":        {makeRequest(DbTypePXC, 4, LoadTypeSomeWrites, 0), "[mysqld]\ntmp_table_size = 64M\n"},
+		"auto dimension":        {makeRequest(DbTypePXC, 998, LoadTypeSomeWrites, 100), "[mysqld]\ntmp_table_size = 64M\n"},
+		"invalid request":       {makeRequest("oracle", 4, LoadTypeSomeWrites, 100), "[mysqld]\ntmp_table_size = 64M\n"},
+		"malformed option file": {makeRequest(DbTypePXC, 4, LoadTypeSomeWrites, 100), "tmp_table_size = 64M\n"},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if err, _ := runAudit(tc.req, tc.myCnf); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
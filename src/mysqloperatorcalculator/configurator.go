@@ -7,7 +7,6 @@ import (
 	"sort"
 	"strconv"
 
-	"github.com/hashicorp/go-version"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -16,6 +15,8 @@ type Configurator struct {
 	families       map[string]Family
 	providerParams map[string]ProviderParam
 	reference      *references
+	traceBranch    string // branch recorded for the parameter being explained
+	traceClamp     string // bound recorded for the parameter being explained
 	//connectionResearch bool
 }
 
@@ -37,6 +38,8 @@ type references struct {
 	connections        int     // raw number of connections
 	tmpTableFootprint  int64   // tempTable expected footprint in memory
 	connBuffersMemTot  int64   // Total mem use for all connection buffers + temp table
+	tmpTableMemTot     int64   // Temp table part of connBuffersMemTot
+	workingSetSaved    int64   // buffer pool memory above the hot set cap, left unused
 	idealBufferPoolDIm int64   // Theoretical ideal BP dimension (rule of the thumb)
 	innoDBBPInstances  int     // assigned number of BP
 	cpusPmm            float64 // cpu assigned to pmm
@@ -47,6 +50,8 @@ type references struct {
 	memoryPmm          float64 // memory assigned to pmm
 	gcscache           int64   // assigned GR GCScache dimension
 	gcscacheFootprint  int64   // GR GCScache expected file footprint in memory
+	writeRatio         float64 // requested share of writes, used only when byRatio
+	byRatio            bool    // load values interpolated by writeRatio instead of indexed by loadID
 	gcscacheLoad       float64 // // TODO make it dynamic as for PXC GCSCache. GR GCScache load adj factor base on memory available
 }
 
@@ -64,12 +69,7 @@ func (c *Configurator) GetAllGaleraProviderOptionsAsString() bytes.Buffer {
 		param := c.providerParams[key]
 		b.WriteString(key)
 		b.WriteString(`=`)
-		if param.Value >= 0 {
-			// Used Fprintf directly to the buffer to avoid intermediary string allocations
-			fmt.Fprintf(&b, param.Literal, strconv.FormatInt(param.Value, 10))
-		} else {
-			b.WriteString(param.Literal)
-		}
+		b.WriteString(param.format(param.Value))
 		b.WriteString(";")
 	}
 	return b
@@ -110,23 +110,36 @@ func (c *Configurator) Init(r ConfigurationRequest, fam map[string]Family, conf
 		memoryPmm:    dim.PmmMemory,
 		gcscacheLoad: 1,
 	}
+	if r.WriteRatio != nil {
+		c.reference.writeRatio = *r.WriteRatio
+		c.reference.byRatio = true
+	}
 
 	loadConnectionFactor, responseMessage, done := c.calculateLoadConnectionFactor(dim, message)
 	if done {
 		return responseMessage, true
 	}
+	if c.throughputCpuOverload() {
+		message.MType = OverutilizingI
+		return message, true
+	}
 
 	c.reference.loadFactor = loadConnectionFactor
-	if c.request.DBType == DbTypePXC {
-		c.reference.idealBufferPoolDIm = int64(c.reference.memoryMySQL * InnoDBPctValuePXC)
-	} else {
-		c.reference.idealBufferPoolDIm = int64(c.reference.memoryMySQL * InnoDBPctValueGR)
-	}
+	c.reference.idealBufferPoolDIm = int64(c.reference.memoryMySQL * c.bufferPoolPct())
 	c.reference.gcacheLoad = c.getGcacheLoad()
 
 	var p ProviderParam
 	c.families = fam
+	for _, family := range c.families {
+		for name, group := range family.Groups {
+			group.Traces = make(map[string]*ParameterTrace)
+			family.Groups[name] = group
+		}
+	}
 	c.providerParams = p.Init()
+	c.topologyProviderParams()
+	c.networkProviderParams()
+	c.applyOverrides()
 
 	return message, false
 }
@@ -143,9 +156,19 @@ func (c *Configurator) calculateLoadConnectionFactor(dim Dimension, message Resp
 		CpuConncetionMillFactor = CpuConncetionMillFactorReadWriteEqual
 	case LoadTypeHeavyWrites:
 		CpuConncetionMillFactor = CpuConncetionMillFactorReadWriteHeavy
+	case LoadTypeAnalytics:
+		CpuConncetionMillFactor = CpuConncetionMillFactorAnalytics
 	default:
 		CpuConncetionMillFactor = CpuConncetionMillFactorReadWriteLight
 	}
+	if factor, ok := c.ratioFloat([4]float64{
+		CpuConncetionMillFactorRead,
+		CpuConncetionMillFactorReadWriteLight,
+		CpuConncetionMillFactorReadWriteEqual,
+		CpuConncetionMillFactorReadWriteHeavy,
+	}); ok {
+		CpuConncetionMillFactor = factor
+	}
 
 	c.reference.loadAdjustmentMax = float64(dim.MysqlCpu) / CpuConncetionMillFactor
 	loadConnectionFactor := float32(c.reference.connections) / float32(c.reference.loadAdjustmentMax)
@@ -179,15 +202,28 @@ func (c *Configurator) ProcessRequest() map[string]Family {
 
 		if c.request.DBType == "group_replication" {
 			c.getGroupReplicationParameters()
+			c.getGroupReplicationTopology()
+			c.getGroupReplicationNetwork()
 
 			group := c.families["mysql"].Groups["configuration_groupReplication"]
-			group.Parameters["loose_group_replication_message_cache_size"] = c.getGCScache(group.Parameters["loose_group_replication_message_cache_size"])
+			c.traced(group, "loose_group_replication_message_cache_size", c.getGCScache,
+				"default × connections per GCS connection weight of the cpus, never below min", traceConnections, traceCpus)
 			c.families["mysql"].Groups["configuration_groupReplication"] = group
+			c.getGroupReplicationConsistency()
 		}
 
 		// Before returning the values we want tore recover any left over from the memory and assign back to the buffer pool by a %
 		c.getInnodbBufferPool(true)
 
+		switch c.request.ProxyType {
+		case ProxyTypeProxySQL:
+			c.getProxySQLParameters()
+		case ProxyTypeRouter:
+			c.getRouterParameters()
+		default:
+			c.getHAProxyParameters()
+		}
+
 		c.getProbesAndResources(FamilyTypeMysql)
 		c.getProbesAndResources(FamilyTypeProxy)
 		c.getProbesAndResources(FamilyTypeMonitor)
@@ -197,23 +233,11 @@ func (c *Configurator) ProcessRequest() map[string]Family {
 }
 
 func (c *Configurator) filterByMySQLVersion() map[string]Family {
-	incomingVStr := fmt.Sprintf("%d.%d.%d", c.request.Mysqlversion.Major, c.request.Mysqlversion.Minor, c.request.Mysqlversion.Patch)
-	incomingV, _ := version.NewVersion(incomingVStr)
-
 	for _, l1Val := range c.families {
 		for _, l2Val := range l1Val.Groups {
 			for l3Key, l3Val := range l2Val.Parameters {
-				if l3Val.Mysqlversions.Min.Major > 0 {
-					minStr := fmt.Sprintf("%d.%d.%d", l3Val.Mysqlversions.Min.Major, l3Val.Mysqlversions.Min.Minor, l3Val.Mysqlversions.Min.Patch)
-					maxStr := fmt.Sprintf("%d.%d.%d", l3Val.Mysqlversions.Max.Major, l3Val.Mysqlversions.Max.Minor, l3Val.Mysqlversions.Max.Patch)
-
-					paramVmin, _ := version.NewVersion(minStr)
-					paramVmax, _ := version.NewVersion(maxStr)
-
-					// Simplified logic to remove out-of-bounds versions
-					if incomingV.LessThan(paramVmin) || incomingV.GreaterThan(paramVmax) {
-						delete(l2Val.Parameters, l3Key)
-					}
+				if !parameterInVersion(l3Val, c.request.Mysqlversion) {
+					delete(l2Val.Parameters, l3Key)
 				}
 			}
 		}
@@ -222,9 +246,21 @@ func (c *Configurator) filterByMySQLVersion() map[string]Family {
 }
 
 // loadValues returns one of four values indexed by current load type [MostlyReads, SomeWrites, EqualReadsWrites, HeavyWrites].
+// With a writeRatio numeric values are interpolated and rounded to an integer, Analytics takes the MostlyReads value.
 func (c *Configurator) loadValues(byLoad [4]string) string {
+	var numbers [4]float64
+	numeric := true
+	for i, v := range byLoad {
+		f, err := strconv.ParseFloat(v, 64)
+		numbers[i] = f
+		numeric = numeric && err == nil
+	}
+	if v, ok := c.ratioFloat(numbers); ok && numeric {
+		return strconv.FormatInt(int64(math.Round(v)), 10)
+	}
+
 	switch c.reference.loadID {
-	case LoadTypeMostlyReads:
+	case LoadTypeMostlyReads, LoadTypeAnalytics:
 		return byLoad[0]
 	case LoadTypeSomeWrites:
 		return byLoad[1]
@@ -238,9 +274,13 @@ func (c *Configurator) loadValues(byLoad [4]string) string {
 }
 
 // loadFloat returns one of four floats indexed by current load type [MostlyReads, SomeWrites, EqualReadsWrites, HeavyWrites].
+// With a writeRatio the value is interpolated, Analytics takes the MostlyReads value.
 func (c *Configurator) loadFloat(byLoad [4]float64) float64 {
+	if v, ok := c.ratioFloat(byLoad); ok {
+		return v
+	}
 	switch c.reference.loadID {
-	case LoadTypeMostlyReads:
+	case LoadTypeMostlyReads, LoadTypeAnalytics:
 		return byLoad[0]
 	case LoadTypeSomeWrites:
 		return byLoad[1]
@@ -253,12 +293,34 @@ func (c *Configurator) loadFloat(byLoad [4]float64) float64 {
 	}
 }
 
+// ratioFloat interpolates the four values by the requested share of writes, linearly between the shares of the
+// load types (loadTypeWriteRatios) and flat outside them; it returns false when the request has no writeRatio
+func (c *Configurator) ratioFloat(byLoad [4]float64) (float64, bool) {
+	if !c.reference.byRatio {
+		return 0, false
+	}
+	ratio := c.reference.writeRatio
+	if ratio <= loadTypeWriteRatios[0] {
+		return byLoad[0], true
+	}
+	for i := 1; i < len(loadTypeWriteRatios); i++ {
+		if ratio <= loadTypeWriteRatios[i] {
+			weight := (ratio - loadTypeWriteRatios[i-1]) / (loadTypeWriteRatios[i] - loadTypeWriteRatios[i-1])
+			return byLoad[i-1]*(1-weight) + byLoad[i]*weight, true
+		}
+	}
+	return byLoad[3], true
+}
+
 func (c *Configurator) getGcache() {
 	c.reference.gcache = int64(float64(c.reference.innodbRedoLogDim) * c.reference.gcacheLoad)
 	if c.reference.gcache > (c.reference.memoryLeftover / 3) {
 		c.reference.gcache = c.reference.memoryLeftover / 3
 	}
-
+	// Only pinned values can leave no memory at this point
+	if c.reference.gcache < 0 {
+		c.reference.gcache = 0
+	}
 	gcacheFootPrintFactor := c.loadFloat([4]float64{
 		GcacheFootPrintFactorRead,
 		GcacheFootPrintFactorLightWrite,
@@ -266,6 +328,14 @@ func (c *Configurator) getGcache() {
 		0.5,
 	})
 
+	// The gcache of an IST window replaces the redo log one, a pinned one is taken as it is, the buffer pool final pass pays for it
+	if gcache, ok := c.istGcache(gcacheFootPrintFactor); ok {
+		c.reference.gcache = gcache
+	}
+	if gcache, ok := c.pinnedInt("gcache.size"); ok {
+		c.reference.gcache = gcache
+	}
+
 	c.reference.gcacheFootprint = int64(math.Ceil(float64(c.reference.gcache) * gcacheFootPrintFactor))
 	c.reference.memoryLeftover -= c.reference.gcacheFootprint
 }
@@ -286,9 +356,15 @@ func (c *Configurator) getConnectionBuffers() {
 	group := c.families["mysql"].Groups["configuration_connection"]
 	//group.Parameters["binlog_cache_size"] = c.paramBinlogCacheSize(group.Parameters["binlog_cache_size"])
 	//group.Parameters["binlog_stmt_cache_size"] = c.paramBinlogCacheSize(group.Parameters["binlog_stmt_cache_size"])
-	group.Parameters["join_buffer_size"] = c.paramJoinBuffer(group.Parameters["join_buffer_size"])
-	group.Parameters["read_rnd_buffer_size"] = c.paramReadRndBuffer(group.Parameters["read_rnd_buffer_size"])
-	group.Parameters["sort_buffer_size"] = c.paramSortBuffer(group.Parameters["sort_buffer_size"])
+	if c.reference.loadID == LoadTypeAnalytics {
+		c.getAnalyticsConnectionBuffers(group)
+	} else {
+		c.traced(group, "join_buffer_size", c.paramJoinBuffer, "per connection buffer by load type (256KB reads to 1MB writes)", traceLoadID)
+		c.traced(group, "read_rnd_buffer_size", c.paramReadRndBuffer, "per connection buffer by load type (256KB reads to 691KB writes)", traceLoadID)
+		c.traced(group, "sort_buffer_size", c.paramSortBuffer, "per connection buffer by load type (256KB reads to 2MB writes)", traceLoadID)
+	}
+
+	c.getStatusTmpTableSize(group)
 
 	c.calculateTmpTableFootprint(group.Parameters["tmp_table_size"])
 	c.sumConnectionBuffers(group.Parameters)
@@ -321,6 +397,9 @@ func (c *Configurator) calculateTmpTableFootprint(inParameter Parameter) {
 	c.reference.tmpTableFootprint, _ = strconv.ParseInt(inParameter.Value, 10, 64)
 
 	multiplier := c.loadFloat([4]float64{0.2, 0.1, 0.3, 0.05})
+	if c.reference.loadID == LoadTypeAnalytics {
+		multiplier = analyticsTmpTableShare
+	}
 
 	c.reference.tmpTableFootprint = int64(float64(c.reference.tmpTableFootprint) * multiplier)
 }
@@ -337,13 +416,20 @@ func (c *Configurator) sumConnectionBuffers(params map[string]Parameter) {
 	possibleConnectionTmp := float64(c.reference.connections) * float64(c.reference.loadFactor)
 	possibleTmpMemPressure := int64(math.Floor(possibleConnectionTmp)) * c.reference.tmpTableFootprint
 
+	c.reference.tmpTableMemTot = possibleTmpMemPressure
 	c.reference.connBuffersMemTot = (totMemory * int64(possibleConnectionTmp)) + possibleTmpMemPressure
 	c.reference.memoryLeftover = int64(c.reference.memoryMySQL) - c.reference.connBuffersMemTot
 }
 
 func (c *Configurator) getInnodbRedolog() {
 	group := c.families["mysql"].Groups["configuration_innodb"]
-	group.Parameters["innodb_log_file_size"] = c.getRedologDimensionTot(group.Parameters["innodb_log_file_size"])
+	c.traced(group, "innodb_redo_log_capacity", c.getRedologDimensionTot,
+		"ideal buffer pool × redo index (0.6 reads, 0.7 light OLTP, 0.8 heavy OLTP, 0.9 writes, + 0.5 × loadFactor, at most 1.0)",
+		traceIdealBufferPool, traceLoadID, traceLoadFactor)
+	c.traced(group, "innodb_log_files_in_group", c.getRedologfilesNumber,
+		"number of files by redo log size in MB, fewer when mainly reads or analytics", traceInnodbRedoLogDim, traceLoadID)
+	c.traced(group, "innodb_log_file_size", c.getRedologFileSize,
+		"innodb_redo_log_capacity / innodb_log_files_in_group", traceInnodbRedoLogDim)
 	c.families["mysql"].Groups["configuration_innodb"] = group
 }
 
@@ -361,50 +447,71 @@ func (c *Configurator) getRedologDimensionTot(inParameter Parameter) Parameter {
 	case LoadTypeHeavyWrites:
 		// TODO this is mainly in case of injest and it may need more calculation, probably if connected to PMM for now is good like this
 		redologIndex = float64(0.9 + (0.5 * c.reference.loadFactor))
+	case LoadTypeAnalytics:
+		// a reporting replica only writes what it replicates, the index of mainly reads
+		redologIndex = float64(0.6 + (0.5 * c.reference.loadFactor))
 	default:
 		redologIndex = float64(0.6 + (0.5 * c.reference.loadFactor))
 	}
+	if base, ok := c.ratioFloat([4]float64{0.6, 0.7, 0.8, 0.9}); ok {
+		// same float32 arithmetic as the load types, a ratio on their share gives the same redo log
+		redologIndex = float64(float32(base) + (0.5 * c.reference.loadFactor))
+	}
 
 	if redologIndex > float64(1.0) {
 		redologIndex = 1.0
+		c.branch("redo index capped at 1.0")
+	} else if c.reference.loadID == LoadTypeAnalytics {
+		c.branch("analytics redo index %.2f", redologIndex)
+	} else {
+		c.branch("redo index %.2f", redologIndex)
 	}
 	redologTotDimension = int64(float64(baseDim) * redologIndex)
+	if capacity, ok := c.throughputRedoLog(inParameter); ok {
+		redologTotDimension = capacity
+	}
+	if capacity, ok := c.pinnedInt("innodb_redo_log_capacity"); ok {
+		c.branch("innodb_redo_log_capacity pinned")
+		redologTotDimension = capacity
+	}
 	c.reference.innodbRedoLogDim = redologTotDimension
 
-	// Access map once, modify directly
-	group := c.families["mysql"].Groups["configuration_innodb"]
-
-	parameterIbC := group.Parameters["innodb_redo_log_capacity"]
-	parameterIbC.Value = strconv.FormatInt(c.reference.innodbRedoLogDim, 10)
-	group.Parameters["innodb_redo_log_capacity"] = parameterIbC
-
-	parameter := group.Parameters["innodb_log_files_in_group"]
-	parameter = c.getRedologfilesNumber(redologTotDimension, parameter)
-	group.Parameters["innodb_log_files_in_group"] = parameter
+	inParameter.Value = strconv.FormatInt(redologTotDimension, 10)
+	return inParameter
+}
 
-	a, _ := strconv.ParseInt(parameter.Value, 10, 64)
-	if a > 0 {
-		inParameter.Value = strconv.FormatInt(redologTotDimension/a, 10)
+// getRedologFileSize splits the redo log capacity in innodb_log_files_in_group files
+func (c *Configurator) getRedologFileSize(parameter Parameter) Parameter {
+	files, _ := strconv.ParseInt(c.families["mysql"].Groups["configuration_innodb"].Parameters["innodb_log_files_in_group"].Value, 10, 64)
+	if files > 0 {
+		c.branch("%d files", files)
+		parameter.Value = strconv.FormatInt(c.reference.innodbRedoLogDim/files, 10)
 	}
-
-	// Save back to map
-	c.families["mysql"].Groups["configuration_innodb"] = group
-	return inParameter
+	return parameter
 }
 
-func (c *Configurator) getRedologfilesNumber(dimension int64, parameter Parameter) Parameter {
-	dimMB := float64(dimension) / (1024 * 1024)
+// getRedologfilesNumber picks the number of redo log files by the redo log size, mainly reads and analytics take fewer.
+// A number of files cannot be interpolated: with a writeRatio it is snapped to the nearest load type
+func (c *Configurator) getRedologfilesNumber(parameter Parameter) Parameter {
+	dimMB := float64(c.reference.innodbRedoLogDim) / (1024 * 1024)
+	// analytics writes only what it replicates, it takes the files of mainly reads
+	reads := c.reference.loadID == LoadTypeMostlyReads || c.reference.loadID == LoadTypeAnalytics
 
 	switch {
 	case dimMB < 500:
+		c.branch("below 500MB")
 		parameter.Value = "2"
 	case dimMB >= 500 && dimMB <= 1000:
-		parameter.Value = map[bool]string{true: "2", false: "3"}[c.reference.loadID == 1]
+		c.branch("500MB to 1000MB")
+		parameter.Value = map[bool]string{true: "2", false: "3"}[reads]
 	case dimMB > 1000 && dimMB <= 2000:
-		parameter.Value = map[bool]string{true: "3", false: "5"}[c.reference.loadID == 1]
+		c.branch("1000MB to 2000MB")
+		parameter.Value = map[bool]string{true: "3", false: "5"}[reads]
 	case dimMB > 2000 && dimMB <= 4000:
-		parameter.Value = map[bool]string{true: "5", false: "8"}[c.reference.loadID == 1]
+		c.branch("2000MB to 4000MB")
+		parameter.Value = map[bool]string{true: "5", false: "8"}[reads]
 	default:
+		c.branch("above 4000MB, one file every 400MB")
 		parameter.Value = strconv.FormatFloat(math.Floor(dimMB/400), 'f', 0, 64)
 	}
 
@@ -418,32 +525,65 @@ func (c *Configurator) getGcacheLoad() float64 {
 func (c *Configurator) getInnodbBufferPool(final bool) {
 	group := c.families["mysql"].Groups["configuration_innodb"]
 
+	setBufferPool := func(p Parameter) Parameter { return c.paramInnoDBBufferPool(p, final) }
 	if !final {
-		group.Parameters["innodb_buffer_pool_size"] = c.paramInnoDBBufferPool(group.Parameters["innodb_buffer_pool_size"], final)
-		group.Parameters["innodb_buffer_pool_instances"] = c.paramInnoDBBufferPoolInstances(group.Parameters["innodb_buffer_pool_instances"])
+		c.traced(group, "innodb_buffer_pool_size", setBufferPool,
+			"memory left after connection buffers × buffer pool share of the dbtype, 90% of it for analytics", traceMemoryLeftover, traceDBType, traceLoadID)
+		c.traced(group, "innodb_buffer_pool_instances", c.paramInnoDBBufferPoolInstances,
+			"by buffer pool GB per mysql core: 2 per core above 1, 1 per core above 0.4, 1 every 2 cores otherwise; 1 up to 2 cores", traceCpus, traceCpusMySQL, traceInnoDBbpSize)
 	} else {
-		group.Parameters["innodb_buffer_pool_size"] = c.paramInnoDBBufferPool(group.Parameters["innodb_buffer_pool_size"], final)
+		c.traced(group, "innodb_buffer_pool_size", setBufferPool,
+			"first pass plus a share (20% to 85%) of the memory left over, or minus the memory overcommitted by the other consumers",
+			traceInnoDBbpSize, traceMemoryLeftover, traceMemoryMySQL, traceDBType)
 	}
 	c.families["mysql"].Groups["configuration_innodb"] = group
 }
 
 func (c *Configurator) getInnodbParameters() {
 	group := c.families["mysql"].Groups["configuration_innodb"]
-	group.Parameters["innodb_adaptive_hash_index"] = c.paramInnoDBAdaptiveHashIndex(group.Parameters["innodb_adaptive_hash_index"])
+	c.traced(group, "innodb_adaptive_hash_index", c.paramInnoDBAdaptiveHashIndex, "enabled for mainly reads only", traceLoadID)
 	//group.Parameters["innodb_page_cleaners"] = c.paramInnoDBBufferPoolCleaners(group.Parameters["innodb_buffer_pool_instances"])
-	group.Parameters["innodb_purge_threads"] = c.paramInnoDPurgeThreads(group.Parameters["innodb_purge_threads"])
-	group.Parameters["innodb_io_capacity_max"] = c.paramInnoDBIOCapacityMax(group.Parameters["innodb_io_capacity_max"])
-	group.Parameters["innodb_parallel_read_threads"] = c.paramInnoDBinnodb_parallel_read_threads(group.Parameters["innodb_parallel_read_threads"])
+	c.traced(group, "innodb_purge_threads", c.paramInnoDPurgeThreads, "4, above 4 cores mysql cores × cache load of the dbtype", traceCpus, traceCpusMySQL, traceGcacheLoad, traceGcscacheLoad, traceDBType)
+	if c.storageIops() > 0 {
+		c.traced(group, "innodb_io_capacity_max", c.paramInnoDBIOCapacityMax,
+			"storage IOPS, at least innodb_io_capacity", traceIops)
+	} else {
+		c.traced(group, "innodb_io_capacity_max", c.paramInnoDBIOCapacityMax, "by load type (28000 reads to 20000 writes)", traceLoadID)
+	}
+	c.traced(group, "innodb_parallel_read_threads", c.paramInnoDBinnodb_parallel_read_threads,
+		"one thread per mysql core above 2 cores, two per core and at least 4 for analytics, up to 256", traceCpusMySQL, traceLoadID)
+	if c.storageIops() > 0 {
+		c.traced(group, "innodb_io_capacity", c.paramInnoDBIOCapacity,
+			"half the storage IOPS, at least the pages the throughput target dirties, never below the default", traceIops)
+		c.traced(group, "innodb_flush_neighbors", c.paramInnoDBFlushNeighbors,
+			"on below 1000 IOPS (rotational), off otherwise", traceIops)
+		c.traced(group, "innodb_read_io_threads", c.paramInnoDBIOThreads,
+			"one thread every 2000 IOPS, 4 to 64", traceIops)
+		c.traced(group, "innodb_write_io_threads", c.paramInnoDBIOThreads,
+			"one thread every 2000 IOPS, 4 to 64", traceIops)
+	} else if c.request.Throughput != nil {
+		c.traced(group, "innodb_io_capacity", c.paramInnoDBIOCapacity,
+			"writes/sec × pages each write dirties (avgWriteBytes / 16KiB, at least 1), never below the default", traceLoadID)
+	}
+	if c.reference.loadID == LoadTypeAnalytics {
+		c.traced(group, "innodb_old_blocks_pct", c.paramInnoDBOldBlocksPct,
+			"smaller old sublist for analytics, scans do not evict the hot pages", traceLoadID)
+	}
 	c.families["mysql"].Groups["configuration_innodb"] = group
 }
 
 func (c *Configurator) getGroupReplicationParameters() {
 	group := c.families["mysql"].Groups["configuration_groupReplication"]
-	group.Parameters["loose_group_replication_member_expel_timeout"] = c.paramGroupReplicationMemberExpelTimeout(group.Parameters["loose_group_replication_member_expel_timeout"])
-	group.Parameters["loose_group_replication_autorejoin_tries"] = c.paramGroupReplicationAutorejoinTries(group.Parameters["loose_group_replication_autorejoin_tries"])
-	group.Parameters["loose_group_replication_communication_max_message_size"] = c.paramGroupReplicationMessageMaxSize(group.Parameters["loose_group_replication_communication_max_message_size"])
+	c.traced(group, "loose_group_replication_member_expel_timeout", c.paramGroupReplicationMemberExpelTimeout,
+		"value × loadFactor × √(nodes / 3), never below the default nor 100 round trips", traceLoadFactor, traceNodes, traceRtt)
+	c.traced(group, "loose_group_replication_autorejoin_tries", c.paramGroupReplicationAutorejoinTries,
+		"max × loadFactor, never below the default", traceLoadFactor)
+	c.traced(group, "loose_group_replication_communication_max_message_size",
+		func(p Parameter) Parameter { return c.networkMessageMaxSize(c.paramGroupReplicationMessageMaxSize(p)) },
+		"value × load multiplier (1.0, 1.5, 2.0, 2.2), never above the default, 2MiB on a WAN", traceLoadID, traceRtt)
 	//group.Parameters["loose_group_replication_poll_spin_loops"] = c.paramGroupReplicationPollSpinLoops(group.Parameters["loose_group_replication_poll_spin_loops"])
-	group.Parameters["loose_group_replication_flow_control_period"] = c.paramGroupReplicationFlowControlPeriod(group.Parameters["loose_group_replication_flow_control_period"])
+	c.traced(group, "loose_group_replication_flow_control_period", c.paramGroupReplicationFlowControlPeriod,
+		"max × (1 - loadFactor) × √(nodes / 3), between min and max", traceLoadFactor, traceNodes)
 	c.families["mysql"].Groups["configuration_groupReplication"] = group
 }
 
@@ -457,9 +597,15 @@ func (c *Configurator) paramInnoDBAdaptiveHashIndex(parameter Parameter) Paramet
 }
 
 func (c *Configurator) paramInnoDBBufferPool(parameter Parameter, final bool) Parameter {
-	bufferPollPct := InnoDBPctValueGR
-	if c.request.DBType == "pxc" {
-		bufferPollPct = InnoDBPctValuePXC
+	bufferPollPct := c.bufferPoolPct()
+
+	// A pinned buffer pool is not resized, memory left over stays unused and EvaluateResources judges the overcommit
+	if bufferPool, ok := c.pinnedInt(parameter.Name); ok {
+		if !final {
+			c.reference.innoDBbpSize = bufferPool
+			c.reference.memoryLeftover -= bufferPool
+		}
+		return parameter
 	}
 
 	if !final {
@@ -475,26 +621,26 @@ func (c *Configurator) paramInnoDBBufferPool(parameter Parameter, final bool) Pa
 		// We identify if the allocations have brought the memory to be negative in that case we can only take it back
 		// from the bufferpool
 		if c.reference.memoryLeftover > 0 {
+			c.branch("memory left over returned to the buffer pool")
 			reassignFreeMemory := c.CalculateReturnBytes(c.reference.memoryLeftover)
 			bufferPool = c.reference.innoDBbpSize + reassignFreeMemory
 			c.reference.memoryLeftover -= reassignFreeMemory
 		} else {
 			// I know is cumbersome and I can simply use the "+" operand, but that will confuse reading
 			// the memory left over at this point is expected to be negative
+			c.branch("memory overcommitted taken back from the buffer pool")
 			bufferPool = c.reference.innoDBbpSize - int64(math.Abs(float64(c.reference.memoryLeftover)))
 			c.reference.memoryLeftover = 0
 
 			// Enforce minimum buffer pool floor to prevent going dangerously low
-			minPct := MinLimitGR
-			if c.request.DBType == "pxc" {
-				minPct = MinLimitPXC
-			}
-			minBufferPool := int64(c.reference.memoryMySQL * minPct)
+			minBufferPool := int64(c.reference.memoryMySQL * minLimitByDBType(c.request.DBType))
 			if bufferPool < minBufferPool {
 				bufferPool = minBufferPool
+				c.clamped("min limit", int(minBufferPool))
 			}
 		}
 
+		bufferPool = c.capBufferPoolByWorkingSet(bufferPool)
 		parameter.Value = strconv.FormatInt(bufferPool, 10)
 		c.reference.innoDBbpSize = bufferPool
 
@@ -502,6 +648,38 @@ func (c *Configurator) paramInnoDBBufferPool(parameter Parameter, final bool) Pa
 	return parameter
 }
 
+// bufferPoolPctByDBType returns the buffer pool ceiling as a fraction of the MySQL memory
+func bufferPoolPctByDBType(DBType string) float64 {
+	switch DBType {
+	case DbTypePXC:
+		return InnoDBPctValuePXC
+	case DbTypeAsync:
+		return InnoDBPctValueAsync
+	default:
+		return InnoDBPctValueGR
+	}
+}
+
+// bufferPoolPct returns the buffer pool ceiling of the request, analytics takes a part of the dbtype one
+func (c *Configurator) bufferPoolPct() float64 {
+	if c.reference.loadID == LoadTypeAnalytics {
+		return bufferPoolPctByDBType(c.request.DBType) * analyticsBufferPoolShare
+	}
+	return bufferPoolPctByDBType(c.request.DBType)
+}
+
+// minLimitByDBType returns the buffer pool floor used to enforce and evaluate the allocation
+func minLimitByDBType(DBType string) float64 {
+	switch DBType {
+	case DbTypeGroupReplication:
+		return MinLimitGR
+	case DbTypeAsync:
+		return MinLimitAsync
+	default:
+		return MinLimitPXC
+	}
+}
+
 // CalculateReturnBytes determines the number of bytes to return based on input size.
 func (c *Configurator) CalculateReturnBytes(incomingBytes int64) int64 {
 	Megabyte := 1024 * 1024
@@ -542,10 +720,13 @@ func (c *Configurator) paramInnoDBBufferPoolInstances(parameter Parameter) Param
 
 		factor := bpSizeGB / maxCpus
 		if factor > 1 {
+			c.branch("%.2fGB per core, above 1", factor)
 			instances = int(maxCpus * 2)
 		} else if factor > 0.4 {
+			c.branch("%.2fGB per core, above 0.4", factor)
 			instances = int(maxCpus)
 		} else {
+			c.branch("%.2fGB per core, up to 0.4", factor)
 			instances = int(math.Ceil(maxCpus / 2))
 		}
 		parameter.Value = strconv.Itoa(instances)
@@ -555,6 +736,7 @@ func (c *Configurator) paramInnoDBBufferPoolInstances(parameter Parameter) Param
 
 	if instances > 64 {
 		instances = 64
+		c.clamped("hard limit", instances)
 		parameter.Value = strconv.Itoa(instances)
 	}
 	c.reference.innoDBBPInstances = instances
@@ -569,6 +751,7 @@ func (c *Configurator) paramInnoDBBufferPoolCleaners(parameter Parameter) Parame
 func (c *Configurator) paramInnoDPurgeThreads(parameter Parameter) Parameter {
 	threads := 4
 	if (c.reference.cpus / 1000) > 4 {
+		c.branch("above 4 cores")
 		adjValue := c.reference.gcscacheLoad
 		if c.request.DBType == "pxc" {
 			adjValue = c.reference.gcacheLoad
@@ -579,6 +762,7 @@ func (c *Configurator) paramInnoDPurgeThreads(parameter Parameter) Parameter {
 
 	if threads > 32 {
 		threads = 32
+		c.clamped("hard limit", threads)
 	}
 
 	parameter.Value = strconv.Itoa(threads)
@@ -586,25 +770,42 @@ func (c *Configurator) paramInnoDPurgeThreads(parameter Parameter) Parameter {
 }
 
 func (c *Configurator) paramInnoDBIOCapacityMax(parameter Parameter) Parameter {
+	// the bursts can use all the IOPS of the storage class, never less than innodb_io_capacity
+	if iops := c.storageIops(); iops > 0 {
+		c.branch("storage IOPS")
+		parameter.Value = strconv.FormatInt(int64(math.Max(float64(iops), float64(c.storageIOCapacity()))), 10)
+		return parameter
+	}
 	parameter.Value = c.loadValues([4]string{"28000", "24000", "20000", "20000"})
+	// the flushing bursts must be able to go above a throughput innodb_io_capacity
+	if capacity := c.throughputIOCapacity(); capacity > 0 {
+		if current, _ := strconv.ParseInt(parameter.Value, 10, 64); 2*capacity > current {
+			c.branch("twice the throughput innodb_io_capacity")
+			parameter.Value = strconv.FormatInt(2*capacity, 10)
+		}
+	}
 	return parameter
 }
 
 func (c *Configurator) getServerParameters() {
 	group := c.families["mysql"].Groups["configuration_server"]
-	group.Parameters["max_connections"] = c.paramServerMaxConnections(group.Parameters["max_connections"])
+	c.traced(group, "max_connections", c.paramServerMaxConnections, "connections plus the administrative slots", traceConnections)
 	// TODO re-enable once we have better TP handling in PS
 	//group.Parameters["thread_pool_size"] = c.paramServerThreadPool(group.Parameters["thread_pool_size"])
 	//group.Parameters["table_definition_cache"] = c.paramServerTableDefinitionCache(group.Parameters["table_definition_cache"])
 	//group.Parameters["table_open_cache"] = c.paramServerTableOpenCache(group.Parameters["table_open_cache"])
 	//group.Parameters["thread_stack"] = c.paramServerThreadStack(group.Parameters["thread_stack"])
 	//group.Parameters["table_open_cache_instances"] = c.paramServerTableOpenCacheInstances(group.Parameters["table_open_cache_instances"])
-	group.Parameters["thread_cache_size"] = c.paramServerThreadCacheSize(group.Parameters["thread_cache_size"])
+	c.traced(group, "thread_cache_size", c.paramServerThreadCacheSize, "requested connections", traceConnections)
+	if c.reference.loadID == LoadTypeAnalytics {
+		c.traced(group, "temptable_max_ram", c.paramTemptableMaxRAM,
+			"temporary tables memory of the connection buffers, at least one tmp_table_size", traceConnections, traceLoadFactor)
+	}
 	c.families["mysql"].Groups["configuration_server"] = group
 }
 
 func (c *Configurator) paramServerMaxConnections(parameter Parameter) Parameter {
-	parameter.Value = strconv.Itoa(c.reference.connections + 2)
+	parameter.Value = strconv.Itoa(c.reference.connections + AdminConnections)
 	return parameter
 }
 
@@ -650,10 +851,14 @@ func (c *Configurator) getGaleraProvider(inParameter Parameter) Parameter {
 			} else if param.Value >= 0 {
 				param.Value = param.Defvalue
 			}
+			param.Value = c.scaleProviderByNodes(key, param.Value)
+			param.Value = c.scaleProviderByNetwork(key, param.Value)
+		}
+		if pinned, ok := c.pinnedProvider(key); ok {
+			param.Value = pinned
 		}
 		c.providerParams[key] = param
 	}
-
 	asString := c.GetAllGaleraProviderOptionsAsString()
 	inParameter.Value = asString.String()
 	return inParameter
@@ -661,10 +866,21 @@ func (c *Configurator) getGaleraProvider(inParameter Parameter) Parameter {
 
 func (c *Configurator) getGaleraParameters() {
 	group := c.families["mysql"].Groups["configuration_galera"]
-	group.Parameters["wsrep-provider-options"] = c.getGaleraProvider(group.Parameters["wsrep-provider-options"])
-	group.Parameters["wsrep_sync_wait"] = c.getGaleraSyncWait(group.Parameters["wsrep_sync_wait"])
-	group.Parameters["wsrep_slave_threads"] = c.getGaleraSlaveThreads(group.Parameters["wsrep_slave_threads"])
-	group.Parameters["wsrep_trx_fragment_size"] = c.getGaleraFragmentSize(group.Parameters["wsrep_trx_fragment_size"])
+	c.traced(group, "wsrep-provider-options", c.getGaleraProvider,
+		"gcache.size from the redo log and memory left over, other options max × loadFactor, suspect and inactive timeouts and single-primary fc_limit × √(nodes / 3), timeouts and send windows never below the round trips",
+		traceGcache, traceLoadFactor, traceNodes, traceMode, traceRtt)
+	if options := c.pinnedProviderOptions(); len(options) > 0 {
+		trace := group.Traces["wsrep-provider-options"]
+		if trace == nil {
+			trace = &ParameterTrace{Replaced: group.Parameters["wsrep-provider-options"].Default}
+			group.Traces["wsrep-provider-options"] = trace
+		}
+		trace.PinnedOptions = options
+	}
+	group.Options = map[string]map[string]Parameter{"wsrep-provider-options": c.providerOptions(group.Parameters["wsrep-provider-options"].Mysqlversions)}
+	c.traced(group, "wsrep_sync_wait", c.getGaleraSyncWait, "3 for light and heavy OLTP, 0 otherwise", traceLoadID)
+	c.traced(group, "wsrep_slave_threads", c.getGaleraSlaveThreads, "one thread every 2 mysql cores, at least 1", traceCpusMySQL)
+	c.traced(group, "wsrep_trx_fragment_size", c.getGaleraFragmentSize, "1MB fragments, large transactions are streamed")
 	c.families["mysql"].Groups["configuration_galera"] = group
 }
 
@@ -680,6 +896,7 @@ func (c *Configurator) getGaleraSyncWait(parameter Parameter) Parameter {
 func (c *Configurator) getGaleraSlaveThreads(parameter Parameter) Parameter {
 	cpus := int(c.reference.cpusMySQL / 1000.0)
 	if cpus <= 1 {
+		c.branch("up to 1 core")
 		cpus = 1
 	} else {
 		cpus = cpus / 2
@@ -688,10 +905,13 @@ func (c *Configurator) getGaleraSlaveThreads(parameter Parameter) Parameter {
 	return parameter
 }
 
+// getGaleraFragmentSize keeps the 1MB fragments, large transactions are replicated while they run
 func (c *Configurator) getGaleraFragmentSize(parameter Parameter) Parameter {
 	return parameter
 }
 
+const ruleProbeTimeout = "max × loadFactor, min when below 1"
+
 func (c *Configurator) getProbesAndResources(family string) {
 	group := c.families[family].Groups["resources"]
 	cpus, memory := c.getResourcesByFamily(family)
@@ -703,37 +923,199 @@ func (c *Configurator) getProbesAndResources(family string) {
 	val := int(math.Ceil(float64(parameter.Max) * float64(c.reference.loadFactor)))
 	if val < 1 {
 		val = int(parameter.Min)
+		c.clamped("min", val)
 	}
 	parameter.Value = strconv.Itoa(val)
-	group.Parameters["timeoutSeconds"] = parameter
+	c.explained(group, "timeoutSeconds", parameter, ruleProbeTimeout, traceLoadFactor)
 	c.families[family].Groups["readinessProbe"] = group
 
 	group = c.families[family].Groups["livenessProbe"]
 	val = int(math.Ceil(float64(parameter.Max) * float64(c.reference.loadFactor)))
 	if val < 1 {
 		val = int(parameter.Min)
+		c.clamped("min", val)
 	}
 	parameter.Value = strconv.Itoa(val)
-	group.Parameters["timeoutSeconds"] = parameter
+	c.explained(group, "timeoutSeconds", parameter, ruleProbeTimeout, traceLoadFactor)
 	c.families[family].Groups["livenessProbe"] = group
 }
 
+func (c *Configurator) getHAProxyParameters() {
+	group := c.families[FamilyTypeProxy].Groups[GroupNameHAProxy]
+	c.traced(group, "maxconn", c.paramHAProxyMaxConn, "connections × nodes", traceConnections, traceNodes)
+	c.traced(group, "ha_connection_timeout", c.paramTimeoutByLoad, ruleTimeoutByLoad, traceLoadFactor)
+	c.families[FamilyTypeProxy].Groups[GroupNameHAProxy] = group
+}
+
+// paramHAProxyMaxConn sizes the proxy to carry the connections accepted by every backend node,
+// admin slots are left to direct connections
+func (c *Configurator) paramHAProxyMaxConn(parameter Parameter) Parameter {
+	val := c.reference.connections * c.request.clusterNodes()
+	if val < int(parameter.Min) {
+		val = int(parameter.Min)
+		c.clamped("min", val)
+	} else if val > int(parameter.Max) {
+		val = int(parameter.Max)
+		c.clamped("max", val)
+	}
+	parameter.Value = strconv.Itoa(val)
+	return parameter
+}
+
+const ruleTimeoutByLoad = "default + (max - default) × loadFactor"
+
+// paramTimeoutByLoad moves a timeout from the default towards the max as the CPU load grows
+func (c *Configurator) paramTimeoutByLoad(parameter Parameter) Parameter {
+	def, _ := strconv.ParseFloat(parameter.Default, 64)
+	val := def + (float64(parameter.Max)-def)*float64(c.reference.loadFactor)
+	parameter.Value = strconv.FormatFloat(math.Ceil(val), 'f', 0, 64)
+	return parameter
+}
+
+func (c *Configurator) getProxySQLParameters() {
+	group := c.families[FamilyTypeProxy].Groups[GroupNameProxySQL]
+	c.traced(group, "mysql-max_connections", c.paramProxySQLMaxConnections, "connections × nodes", traceConnections, traceNodes)
+	c.traced(group, "mysql-threads", c.paramProxySQLThreads, "one thread per proxy core", traceCpusProxy)
+	c.traced(group, "mysql-query_cache_size_MB", c.paramProxySQLQueryCacheSize,
+		"proxy memory share by load type (20%, 15%, 10%, 5%) in MB", traceMemoryProxy, traceLoadID)
+	c.traced(group, "mysql-free_connections_pct", c.paramProxySQLFreeConnectionsPct, "by load type (20% reads to 5% writes)", traceLoadID)
+	c.traced(group, "mysql-connect_timeout_server", c.paramTimeoutByLoad, ruleTimeoutByLoad, traceLoadFactor)
+	c.traced(group, "mysql_servers.max_connections", c.paramProxySQLServerMaxConnections, "requested connections", traceConnections)
+	c.families[FamilyTypeProxy].Groups[GroupNameProxySQL] = group
+}
+
+// paramProxySQLMaxConnections accepts on the frontend the connections of every backend node, as HAProxy maxconn
+func (c *Configurator) paramProxySQLMaxConnections(parameter Parameter) Parameter {
+	parameter.Value = strconv.Itoa(c.reference.connections * c.request.clusterNodes())
+	return parameter
+}
+
+// paramProxySQLThreads assigns one worker thread per core given to the proxy
+func (c *Configurator) paramProxySQLThreads(parameter Parameter) Parameter {
+	threads := int(math.Ceil(c.reference.cpusProxy / 1000))
+	if threads < int(parameter.Min) {
+		threads = int(parameter.Min)
+		c.clamped("min", threads)
+	} else if threads > int(parameter.Max) {
+		threads = int(parameter.Max)
+		c.clamped("max", threads)
+	}
+	parameter.Value = strconv.Itoa(threads)
+	return parameter
+}
+
+// paramProxySQLQueryCacheSize gives the query cache a share of the proxy memory, larger when reads dominate
+func (c *Configurator) paramProxySQLQueryCacheSize(parameter Parameter) Parameter {
+	share := c.loadFloat([4]float64{0.20, 0.15, 0.10, 0.05})
+	parameter.Value = strconv.FormatFloat(math.Floor(c.reference.memoryProxy*share/(1024*1024)), 'f', 0, 64)
+	return parameter
+}
+
+// paramProxySQLFreeConnectionsPct keeps more idle backend connections in the pool for read bursts
+func (c *Configurator) paramProxySQLFreeConnectionsPct(parameter Parameter) Parameter {
+	parameter.Value = c.loadValues([4]string{"20", "15", "10", "5"})
+	return parameter
+}
+
+// paramProxySQLServerMaxConnections caps the pool towards each backend at the requested connections,
+// the admin slots of max_connections stay free for direct connections
+func (c *Configurator) paramProxySQLServerMaxConnections(parameter Parameter) Parameter {
+	parameter.Value = strconv.Itoa(c.reference.connections)
+	return parameter
+}
+
+func (c *Configurator) getRouterParameters() {
+	group := c.families[FamilyTypeProxy].Groups[GroupNameRouter]
+	c.traced(group, "max_total_connections", c.paramRouterMaxTotalConnections,
+		"connections × nodes, within the proxy cores and half of the proxy memory", traceConnections, traceNodes, traceCpusProxy, traceMemoryProxy)
+	c.traced(group, "connect_timeout", c.paramTimeoutByLoad, ruleTimeoutByLoad, traceLoadFactor)
+	c.traced(group, "client_connect_timeout", c.paramTimeoutByLoad, ruleTimeoutByLoad, traceLoadFactor)
+	c.traced(group, "routing_rw.max_connections",
+		func(p Parameter) Parameter { return c.paramRouterRouteMaxConnections(p, c.writers()) }, "connections × the primary, every member in multi-primary", traceConnections, traceMode, traceNodes)
+	c.traced(group, "routing_ro.max_connections",
+		func(p Parameter) Parameter { return c.paramRouterRouteMaxConnections(p, c.request.clusterNodes()-1) }, "connections × the secondaries", traceConnections, traceNodes)
+	c.traced(group, "io.threads", c.paramRouterIOThreads, "one thread per proxy core", traceCpusProxy)
+	c.traced(group, "connection_pool.max_idle_server_connections", c.paramRouterMaxIdleServerConnections,
+		"requested connections, as long as half of the proxy memory holds them", traceConnections, traceMemoryProxy)
+	c.families[FamilyTypeProxy].Groups[GroupNameRouter] = group
+}
+
+// paramRouterMaxTotalConnections accepts the connections of every member, same as HAProxy maxconn,
+// as long as the router cores and the half of its memory the connection pool leaves can carry them
+func (c *Configurator) paramRouterMaxTotalConnections(parameter Parameter) Parameter {
+	val := c.reference.connections * c.request.clusterNodes()
+	if byCpu := int(c.reference.cpusProxy / 1000 * RouterConnectionsPerCore); byCpu < val {
+		val = byCpu
+		c.branch("limited by the proxy cpu")
+	}
+	if byMemory := int(c.reference.memoryProxy * 0.5 / RouterClientConnectionMemory); byMemory < val {
+		val = byMemory
+		c.branch("limited by the proxy memory")
+	}
+	if val < int(parameter.Min) {
+		val = int(parameter.Min)
+		c.clamped("min", val)
+	} else if val > int(parameter.Max) {
+		val = int(parameter.Max)
+		c.clamped("max", val)
+	}
+	parameter.Value = strconv.Itoa(val)
+	return parameter
+}
+
+// paramRouterRouteMaxConnections sizes a route on the number of members behind it,
+// the rw route reaches the primary only, the ro route the secondaries
+func (c *Configurator) paramRouterRouteMaxConnections(parameter Parameter, members int) Parameter {
+	val := c.reference.connections * members
+	if val > int(parameter.Max) {
+		val = int(parameter.Max)
+		c.clamped("max", val)
+	}
+	parameter.Value = strconv.Itoa(val)
+	return parameter
+}
+
+// paramRouterIOThreads assigns one io thread per core given to the router
+func (c *Configurator) paramRouterIOThreads(parameter Parameter) Parameter {
+	threads := int(math.Ceil(c.reference.cpusProxy / 1000))
+	if threads < 1 {
+		threads = 1
+		c.clamped("min", threads)
+	} else if threads > int(parameter.Max) {
+		threads = int(parameter.Max)
+		c.clamped("max", threads)
+	}
+	parameter.Value = strconv.Itoa(threads)
+	return parameter
+}
+
+// paramRouterMaxIdleServerConnections pools up to the requested connections as long as half of the router memory can hold them
+func (c *Configurator) paramRouterMaxIdleServerConnections(parameter Parameter) Parameter {
+	val := c.reference.connections
+	if byMemory := int(c.reference.memoryProxy * 0.5 / RouterPooledConnectionMemory); byMemory < val {
+		val = byMemory
+		c.branch("limited by the proxy memory")
+	}
+	parameter.Value = strconv.Itoa(val)
+	return parameter
+}
+
 func (c *Configurator) setResources(group GroupObj, cpus float64, memory float64) GroupObj {
 	parameter := group.Parameters["request_memory"]
 	parameter.Value = strconv.FormatFloat(memory*0.95, 'f', 0, 64)
-	group.Parameters["request_memory"] = parameter
+	c.explained(group, "request_memory", parameter, "95% of the memory assigned to the family")
 
 	parameter = group.Parameters["limit_memory"]
 	parameter.Value = strconv.FormatFloat(memory, 'f', 0, 64)
-	group.Parameters["limit_memory"] = parameter
+	c.explained(group, "limit_memory", parameter, "memory assigned to the family")
 
 	parameter = group.Parameters["request_cpu"]
 	parameter.Value = strconv.FormatFloat(cpus*0.95, 'f', 0, 64) + "m"
-	group.Parameters["request_cpu"] = parameter
+	c.explained(group, "request_cpu", parameter, "95% of the cpu assigned to the family")
 
 	parameter = group.Parameters["limit_cpu"]
 	parameter.Value = strconv.FormatFloat(cpus, 'f', 0, 64) + "m"
-	group.Parameters["limit_cpu"] = parameter
+	c.explained(group, "limit_cpu", parameter, "cpu assigned to the family")
 
 	return group
 }
@@ -769,13 +1151,76 @@ func (c *Configurator) EvaluateResources(responseMsg ResponseMessage) (ResponseM
 
 	bpPct := float64(c.reference.innoDBbpSize) / c.reference.memory
 	fmt.Fprintf(&b, "%% BP over av memory     = %.2f\n\n", bpPct)
+	if _, ok := c.pinned("innodb_buffer_pool_size"); ok && c.reference.memoryLeftover < 0 {
+		fmt.Fprintf(&b, "memory overcommitted by pinned values = %d\n\n", -c.reference.memoryLeftover)
+	}
+	bpPct = float64(c.bufferPoolJudged()) / c.reference.memory
 	fmt.Fprintf(&b, "memory leftover         = %d\n\n", c.reference.memoryLeftover)
 	fmt.Fprintf(&b, "Load factor cpu        = %.2f\n", c.reference.loadFactor)
 	fmt.Fprintf(&b, "Load mem factor= %.2f\n\n", bpPct)
 
+	breakdown := c.GetResourceBreakdown()
+	responseMsg.Breakdown = &breakdown
 	return c.FillResponseMessage(bpPct, responseMsg, b, c.request.DBType)
 }
 
+// bufferPoolJudged returns the buffer pool EvaluateResources judges against the min limit.
+// A pinned buffer pool is judged on the memory the calculation could have given it, it cannot absorb an overcommit.
+// A buffer pool capped by the hot set is judged on the memory it left, as the one it could have taken
+func (c *Configurator) bufferPoolJudged() int64 {
+	bufferPool := c.reference.innoDBbpSize
+	if _, ok := c.pinned("innodb_buffer_pool_size"); ok {
+		if c.reference.memoryLeftover < 0 {
+			return 0
+		}
+		bufferPool += c.reference.memoryLeftover
+	}
+	if c.reference.workingSetSaved > 0 {
+		bufferPool = c.reference.innoDBbpSize + c.reference.workingSetSaved
+	}
+	return bufferPool
+}
+
+// GetResourceBreakdown returns the resources assigned by the last calculation, the same values EvaluateResources
+// writes in the message text
+func (c *Configurator) GetResourceBreakdown() ResourceBreakdown {
+	breakdown := ResourceBreakdown{
+		Connections: c.reference.connections,
+		LoadFactor:  float64(c.reference.loadFactor),
+		Cpu: CpuBreakdown{
+			Total: c.reference.cpus,
+			Mysql: int(c.reference.cpusMySQL),
+			Proxy: int(c.reference.cpusProxy),
+			Pmm:   int(c.reference.cpusPmm),
+		},
+		Memory: MemoryBreakdown{
+			Total: int64(c.reference.memory),
+			Proxy: int64(c.reference.memoryProxy),
+			Pmm:   int64(c.reference.memoryPmm),
+			Mysql: MysqlMemory{
+				Allocated:         int64(c.reference.memoryMySQL),
+				BufferPool:        c.reference.innoDBbpSize,
+				ConnectionBuffers: c.reference.connBuffersMemTot,
+				TmpTableFootprint: c.reference.tmpTableFootprint,
+				Leftover:          c.reference.memoryLeftover,
+			},
+		},
+	}
+	if c.reference.memory > 0 {
+		breakdown.BufferPoolPct = float64(c.reference.innoDBbpSize) / c.reference.memory
+	}
+
+	switch c.request.DBType {
+	case DbTypePXC:
+		breakdown.Memory.Mysql.Gcache = c.reference.gcache
+		breakdown.Memory.Mysql.GcacheFootprint = c.reference.gcacheFootprint
+	case DbTypeGroupReplication:
+		breakdown.Memory.Mysql.GcsCache = c.reference.gcscache
+		breakdown.Memory.Mysql.GcsCacheFootprint = c.reference.gcscacheFootprint
+	}
+	return breakdown
+}
+
 func (c *Configurator) getResourcesByFamily(family string) (float64, float64) {
 	switch family {
 	case FamilyTypeMysql:
@@ -798,6 +1243,10 @@ func (c *Configurator) paramInnoDBinnodb_parallel_read_threads(parameter Paramet
 	} else if cpus > 256 {
 		threads = 256
 	}
+	if c.reference.loadID == LoadTypeAnalytics {
+		c.branch("analytics")
+		threads = int(math.Min(math.Max(float64(cpus*2), 4), 256))
+	}
 	parameter.Value = strconv.Itoa(threads)
 	return parameter
 }
@@ -826,6 +1275,11 @@ func (c *Configurator) getGCScache(parameter Parameter) Parameter {
 	} else {
 		// If the value calculated is less than the minimum, we adjust the value to minimum value.
 		parameter.Value = strconv.FormatUint(val, 10)
+		c.clamped("min", int(val))
+	}
+
+	if pinned, ok := c.pinned(parameter.Name); ok {
+		parameter.Value = pinned
 	}
 
 	c.reference.gcscacheFootprint, _ = strconv.ParseInt(parameter.Value, 10, 64)
@@ -841,21 +1295,32 @@ func (c *Configurator) paramGroupReplicationMemberExpelTimeout(parameter Paramet
 		log.Warnf("ParseFloat Error: %v", err)
 	}
 
-	val := int(math.Ceil(valS * float64(c.reference.loadFactor)))
+	val := int(math.Ceil(valS * float64(c.reference.loadFactor) * c.topologyFactor()))
 	def, _ := strconv.Atoi(parameter.Default)
 
 	if val < def {
 		val = def
+		c.clamped("default", val)
+	}
+	if rtt := c.networkExpelTimeout(); val < rtt {
+		val = rtt
+		c.clamped("round trips", val)
 	}
 	parameter.Value = strconv.Itoa(val)
 	return parameter
 }
 
+// paramGroupReplicationFlowControlPeriod lowers the period on busy members, every member sends its statistics
+// each period so a larger group takes a longer one
 func (c *Configurator) paramGroupReplicationFlowControlPeriod(parameter Parameter) Parameter {
-	val := int(math.Ceil(float64(parameter.Max) * float64(1.0-c.reference.loadFactor)))
+	val := int(math.Ceil(float64(parameter.Max) * float64(1.0-c.reference.loadFactor) * c.topologyFactor()))
 	mind := int(parameter.Min)
 	if val < mind {
 		val = mind
+		c.clamped("min", val)
+	} else if val > int(parameter.Max) {
+		val = int(parameter.Max)
+		c.clamped("max", val)
 	}
 	parameter.Value = strconv.Itoa(val)
 	return parameter
@@ -866,6 +1331,7 @@ func (c *Configurator) paramGroupReplicationAutorejoinTries(parameter Parameter)
 	def, _ := strconv.Atoi(parameter.Default)
 	if val < def {
 		val = def
+		c.clamped("default", val)
 	}
 	parameter.Value = strconv.Itoa(val)
 	return parameter
@@ -877,25 +1343,15 @@ func (c *Configurator) paramGroupReplicationMessageMaxSize(parameter Parameter)
 		log.Warnf("Atoi Error: %v", err)
 	}
 
-	val := float64(pval)
-	switch c.request.LoadType.Id {
-	case 1:
-		val *= 1.0
-	case 2:
-		val *= 1.5
-	case 3:
-		val *= 2.0
-	case 4:
-		val *= 2.2
-	default:
-		parameter.Value = parameter.Value
-	}
+	val := float64(pval) * c.loadFloat([4]float64{1.0, 1.5, 2.0, 2.2})
 
 	defVl, _ := strconv.ParseUint(parameter.Default, 10, 64)
 	if uint64(val) > defVl {
 		parameter.Value = strconv.FormatUint(defVl, 10)
+		c.clamped("default", int(defVl))
 		return parameter
 	} else if uint64(val) < parameter.Min {
+		c.branch("below min, value kept")
 		return parameter
 	}
 
@@ -903,11 +1359,17 @@ func (c *Configurator) paramGroupReplicationMessageMaxSize(parameter Parameter)
 	return parameter
 }
 
-func (c *Configurator) paramGroupReplicationUnreachableMajorityTimeout(parameter Parameter) Parameter {
-	val := int(math.Ceil(float64(parameter.Max) * float64(c.reference.loadFactor)))
+// paramGroupReplicationUnreachableMajorityTimeout lets the minority side leave once the majority had the time
+// to suspect and expel it, twice over, busy members wait longer
+func (c *Configurator) paramGroupReplicationUnreachableMajorityTimeout(parameter Parameter, expel int) Parameter {
+	val := int(math.Ceil(float64(parameter.Max)*float64(c.reference.loadFactor))) + 2*(expel+groupReplicationSuspicion)
 	min := int(parameter.Min)
 	if val < min {
 		val = min
+		c.clamped("min", val)
+	} else if val > int(parameter.Max) {
+		val = int(parameter.Max)
+		c.clamped("max", val)
 	}
 	parameter.Value = strconv.Itoa(val)
 	return parameter
@@ -954,6 +1416,7 @@ func (c *Configurator) paramServerThreadCacheSize(parameter Parameter) Parameter
 	val := c.request.Connections
 	if val > int(parameter.Max) {
 		val = int(parameter.Max)
+		c.clamped("max", val)
 	}
 
 	parameter.Value = strconv.Itoa(val)
@@ -962,10 +1425,7 @@ func (c *Configurator) paramServerThreadCacheSize(parameter Parameter) Parameter
 
 func (c *Configurator) FillResponseMessage(pct float64, msg ResponseMessage, b bytes.Buffer, DBType string) (ResponseMessage, bool) {
 	overUtilizing := false
-	minlimit := float64(MinLimitPXC)
-	if DBType == "group_replication" {
-		minlimit = float64(MinLimitGR)
-	}
+	minlimit := minLimitByDBType(DBType)
 
 	// Not used anymore the memory leftover is managed dealing with the Bufferpool size
 	//if c.reference.memoryLeftover < 0 {
@@ -999,23 +1459,67 @@ func (c *Configurator) FillResponseMessage(pct float64, msg ResponseMessage, b b
 
 func (c *Configurator) getReplicationParameters() {
 	group := c.families["mysql"].Groups["configuration_replica"]
-	group.Parameters["replica_parallel_workers"] = c.paramReplicaParallelWorkers(group.Parameters["replica_parallel_workers"])
+	c.traced(group, "replica_parallel_workers", c.paramReplicaParallelWorkers,
+		"workers per mysql core (2.5, async by load type 1.0/2.0/2.5/3.0), never below the default", traceCpusMySQL, traceDBType, traceLoadID)
+	if c.request.DBType == DbTypeAsync {
+		c.traced(group, "max_binlog_size", c.paramMaxBinlogSize,
+			"by load type (256MB reads to 1GB writes)", traceLoadID)
+		c.traced(group, "replica_pending_jobs_size_max", c.paramReplicaPendingJobsSizeMax,
+			"64MB per worker, at most 5% of the mysql memory, never below the default", traceCpusMySQL, traceLoadID, traceMemoryMySQL)
+	}
 
 	c.families["mysql"].Groups["configuration_replica"] = group
 }
 
+// paramMaxBinlogSize rotates the binary log sooner on read loads, smaller files are purged closer to
+// binlog_expire_logs_seconds. Write loads keep the 1GB files to limit the rotations
+func (c *Configurator) paramMaxBinlogSize(parameter Parameter) Parameter {
+	parameter.Value = c.loadValues([4]string{"268435456", "536870912", "1073741824", "1073741824"})
+	return parameter
+}
+
+// paramReplicaPendingJobsSizeMax sizes the queue of events waiting for the workers: 64MB for each worker,
+// bounded by 5% of the mysql memory. The default is kept as the floor, a smaller queue stalls the coordinator
+// on large transactions
+func (c *Configurator) paramReplicaPendingJobsSizeMax(parameter Parameter) Parameter {
+	def, _ := strconv.ParseInt(parameter.Default, 10, 64)
+	workers, _ := strconv.ParseInt(c.families["mysql"].Groups["configuration_replica"].Parameters["replica_parallel_workers"].Value, 10, 64)
+
+	value := workers * 64 * mib
+	if limit := int64(float64(c.reference.memoryMySQL) * 0.05); value > limit {
+		value = limit
+		c.branch("limited by the mysql memory")
+	}
+	if value < def {
+		value = def
+		c.branch("default")
+	}
+	parameter.Value = strconv.FormatInt(value, 10)
+	return parameter
+}
+
 // paramReplicaParallelWorkers adjusts the "replica_parallel_workers" parameter value based on available CPU resources.
-// Following the formula of 2.5 workers for available 1000 cpu
+// Following the formula of 2.5 workers for available 1000 cpu. An async replica applies the whole write load of the
+// source alone, so there the workers follow the write share of the load instead
 func (c *Configurator) paramReplicaParallelWorkers(parameter Parameter) Parameter {
 	cpu := c.reference.cpusMySQL
 	value, _ := strconv.Atoi(parameter.Default)
 
+	workersPerCpu := 2.5
+	if c.request.DBType == DbTypeAsync {
+		workersPerCpu = c.loadFloat([4]float64{1.0, 2.0, 2.5, 3.0})
+	}
+
 	if cpu > 1000 {
-		proposedWorkers := int((cpu / 1000) * 2.5)
+		proposedWorkers := int((cpu / 1000) * workersPerCpu)
 		if proposedWorkers > value {
 			value = proposedWorkers
 		}
 	}
+	if c.request.DBType == DbTypeAsync && value > int(parameter.Max) {
+		value = int(parameter.Max)
+		c.clamped("max", value)
+	}
 	parameter.Value = strconv.Itoa(value)
 	return parameter
 
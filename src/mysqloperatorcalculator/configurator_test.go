@@ -2,7 +2,9 @@ package mysqloperatorcalculator
 
 import (
 	"bytes"
+	"math"
 	"strconv"
+	"strings"
 	"testing"
 )
 
@@ -78,6 +80,7 @@ func TestLoadValues_AllLoadTypes(t *testing.T) {
 		{LoadTypeSomeWrites, "B"},
 		{LoadTypeEqualReadsWrites, "C"},
 		{LoadTypeHeavyWrites, "D"},
+		{LoadTypeAnalytics, "A"}, // the rest of the analytics calculation is the mainly reads one
 		{99, "B"}, // unknown ID falls back to index 1 (SomeWrites)
 	}
 	for _, tc := range cases {
@@ -98,6 +101,7 @@ func TestLoadFloat_AllLoadTypes(t *testing.T) {
 		{LoadTypeSomeWrites, 2.0},
 		{LoadTypeEqualReadsWrites, 3.0},
 		{LoadTypeHeavyWrites, 4.0},
+		{LoadTypeAnalytics, 1.0},
 		{99, 1.0}, // unknown ID falls back to index 0 (MostlyReads)
 	}
 	for _, tc := range cases {
@@ -109,6 +113,114 @@ func TestLoadFloat_AllLoadTypes(t *testing.T) {
 	}
 }
 
+func TestLoadFloat_WriteRatio(t *testing.T) {
+	cases := []struct {
+		ratio float64
+		want  float64
+	}{
+		{0.0, 1.0},  // below MostlyReads, flat
+		{0.05, 1.0}, // on the load type shares the values are the same as by ID
+		{0.20, 2.0},
+		{0.35, 2.5},
+		{0.50, 3.0},
+		{0.70, 3.5},
+		{0.90, 4.0},
+		{1.0, 4.0}, // above HeavyWrites, flat
+	}
+	for _, tc := range cases {
+		c := newTestConfigurator(nearestLoadType(tc.ratio), DbTypePXC, 50, 1200, 4*testGB)
+		c.reference.writeRatio = tc.ratio
+		c.reference.byRatio = true
+		if got := c.loadFloat([4]float64{1.0, 2.0, 3.0, 4.0}); math.Abs(got-tc.want) > 1e-9 {
+			t.Errorf("writeRatio=%.2f: loadFloat() = %f, want %f", tc.ratio, got, tc.want)
+		}
+	}
+
+	c := newTestConfigurator(LoadTypeSomeWrites, DbTypePXC, 50, 1200, 4*testGB)
+	c.reference.writeRatio = 0.35
+	c.reference.byRatio = true
+	if got := c.loadValues([4]string{"100", "200", "301", "400"}); got != "251" {
+		t.Errorf("numeric loadValues() = %q, want the rounded interpolation 251", got)
+	}
+	if got := c.loadValues([4]string{"A", "B", "C", "D"}); got != "B" {
+		t.Errorf("non numeric loadValues() = %q, want the load type value B", got)
+	}
+}
+
+func TestCalculate_WriteRatio(t *testing.T) {
+	values := func(req ConfigurationRequest) map[string]string {
+		t.Helper()
+		err, _, families := runCalculate(req)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		out := make(map[string]string)
+		for _, group := range families[FamilyTypeMysql].Groups {
+			for name, p := range group.Parameters {
+				out[name] = p.Value
+			}
+		}
+		return out
+	}
+	byRatio := func(ratio float64) ConfigurationRequest {
+		req := makeRequest(DbTypePXC, 4, 0, 200)
+		req.WriteRatio = &ratio
+		return req
+	}
+
+	// On the share of a load type the result is the load type one
+	light, heavy := values(makeRequest(DbTypePXC, 4, LoadTypeSomeWrites, 200)), values(makeRequest(DbTypePXC, 4, LoadTypeEqualReadsWrites, 200))
+	for name, v := range values(byRatio(0.20)) {
+		if light[name] != v {
+			t.Errorf("writeratio 0.20 %s = %s, load type %d gives %s", name, v, LoadTypeSomeWrites, light[name])
+		}
+	}
+
+	// In between the buffers move smoothly from one load type to the next
+	between := values(byRatio(0.40))
+	for _, name := range []string{"join_buffer_size", "read_rnd_buffer_size", "sort_buffer_size", "innodb_redo_log_capacity"} {
+		low, _ := strconv.ParseInt(light[name], 10, 64)
+		high, _ := strconv.ParseInt(heavy[name], 10, 64)
+		v, _ := strconv.ParseInt(between[name], 10, 64)
+		if low > high {
+			low, high = high, low
+		}
+		if low != high && (v <= low || v >= high) {
+			t.Errorf("writeratio 0.40 %s = %d, want strictly between %d and %d", name, v, low, high)
+		}
+	}
+
+	var conf Configuration
+	conf.Init()
+	var moc MysqlOperatorCalculator
+	if incoming := moc.Init(byRatio(0.40), conf); incoming.LoadType.Id != LoadTypeEqualReadsWrites || incoming.LoadType.Name == "" {
+		t.Errorf("load type must be the closest to the write ratio, got %+v", incoming.LoadType)
+	}
+
+	for name, req := range map[string]ConfigurationRequest{
+		"out of range":       byRatio(1.5),
+		"load type mismatch": func() ConfigurationRequest { r := byRatio(0.9); r.LoadType.Id = LoadTypeMostlyReads; return r }(),
+	} {
+		if err, _, _ := runCalculate(req); err == nil || !strings.Contains(err.Error(), "writeratio") {
+			t.Errorf("%s: expected a writeratio error, got %v", name, err)
+		}
+	}
+}
+
+func TestParamGroupReplicationMessageMaxSize_WriteRatio(t *testing.T) {
+	p := Parameter{Value: "1000000", Default: "10485760"}
+	c := newTestConfigurator(LoadTypeEqualReadsWrites, DbTypeGroupReplication, 50, 1200, 4*testGB)
+	if got := c.paramGroupReplicationMessageMaxSize(p).Value; got != "2000000" {
+		t.Errorf("heavy OLTP: got %s, want 2000000", got)
+	}
+	// between light (1.5) and heavy OLTP (2.0)
+	c.reference.writeRatio = 0.35
+	c.reference.byRatio = true
+	if got := c.paramGroupReplicationMessageMaxSize(p).Value; got != "1750000" {
+		t.Errorf("writeRatio 0.35: got %s, want 1750000", got)
+	}
+}
+
 // ---------------------------------------------------------------------------
 // getGcacheLoad
 // ---------------------------------------------------------------------------
@@ -417,3 +529,177 @@ func TestFillResponseMessage_OkReturnsFalse(t *testing.T) {
 		t.Error("expected overUtilizing=false for bpPct=0.75 on PXC")
 	}
 }
+
+// ---------------------------------------------------------------------------
+// ProxySQL parameters
+// ---------------------------------------------------------------------------
+
+func TestParamProxySQL(t *testing.T) {
+	cases := []struct {
+		loadID         int
+		cpusProxy      float64
+		wantThreads    string
+		wantQueryCache string
+		wantFreePct    string
+	}{
+		{LoadTypeMostlyReads, 350, "1", "20", "20"},
+		{LoadTypeSomeWrites, 1500, "2", "15", "15"},
+		{LoadTypeEqualReadsWrites, 4000, "4", "10", "10"},
+		{LoadTypeHeavyWrites, 300000, "256", "5", "5"},
+	}
+	for _, tc := range cases {
+		c := newTestConfigurator(tc.loadID, DbTypeGroupReplication, 100, 2000, 4*testGB)
+		c.reference.cpusProxy = tc.cpusProxy
+		c.reference.memoryProxy = 100 * testMB
+		p := Parameter{Min: 1, Max: 256}
+
+		if got := c.paramProxySQLThreads(p).Value; got != tc.wantThreads {
+			t.Errorf("loadID=%d paramProxySQLThreads: got %s, want %s", tc.loadID, got, tc.wantThreads)
+		}
+		if got := c.paramProxySQLQueryCacheSize(p).Value; got != tc.wantQueryCache {
+			t.Errorf("loadID=%d paramProxySQLQueryCacheSize: got %s, want %s", tc.loadID, got, tc.wantQueryCache)
+		}
+		if got := c.paramProxySQLFreeConnectionsPct(p).Value; got != tc.wantFreePct {
+			t.Errorf("loadID=%d paramProxySQLFreeConnectionsPct: got %s, want %s", tc.loadID, got, tc.wantFreePct)
+		}
+		if got := c.paramProxySQLMaxConnections(p).Value; got != strconv.Itoa(100*DefaultClusterNodes) {
+			t.Errorf("loadID=%d paramProxySQLMaxConnections: got %s, want %d", tc.loadID, got, 100*DefaultClusterNodes)
+		}
+		if got := c.paramProxySQLServerMaxConnections(p).Value; got != "100" {
+			t.Errorf("loadID=%d paramProxySQLServerMaxConnections: got %s, want 100", tc.loadID, got)
+		}
+	}
+}
+
+// ---------------------------------------------------------------------------
+// MySQL Router parameters
+// ---------------------------------------------------------------------------
+
+func TestParamRouter(t *testing.T) {
+	c := newTestConfigurator(LoadTypeSomeWrites, DbTypeGroupReplication, 200, 2000, 4*testGB)
+	c.reference.cpusProxy = 1500
+	c.reference.memoryProxy = 300 * testMB
+	p := Parameter{Max: 65535}
+
+	if got := c.paramRouterMaxTotalConnections(p).Value; got != strconv.Itoa(200*DefaultClusterNodes) {
+		t.Errorf("paramRouterMaxTotalConnections: got %s, want %d", got, 200*DefaultClusterNodes)
+	}
+	if got := c.paramRouterRouteMaxConnections(p, 1).Value; got != "200" {
+		t.Errorf("paramRouterRouteMaxConnections(rw): got %s, want 200", got)
+	}
+	if got := c.paramRouterRouteMaxConnections(p, DefaultClusterNodes-1).Value; got != strconv.Itoa(200*(DefaultClusterNodes-1)) {
+		t.Errorf("paramRouterRouteMaxConnections(ro): got %s, want %d", got, 200*(DefaultClusterNodes-1))
+	}
+	if got := c.paramRouterIOThreads(Parameter{Max: 1024}).Value; got != "2" {
+		t.Errorf("paramRouterIOThreads: got %s, want 2", got)
+	}
+	// Half of 300MB holds 150 pooled connections of 1MB, less than the 200 requested
+	if got := c.paramRouterMaxIdleServerConnections(p).Value; got != "150" {
+		t.Errorf("paramRouterMaxIdleServerConnections: got %s, want 150", got)
+	}
+
+	// Half of 50MB holds 100 client connections of 256KB, 100m of router cpu carries 200
+	c.reference.memoryProxy = 50 * testMB
+	if got := c.paramRouterMaxTotalConnections(p).Value; got != "100" {
+		t.Errorf("paramRouterMaxTotalConnections by memory: got %s, want 100", got)
+	}
+	c.reference.memoryProxy = 200 * testMB
+	c.reference.cpusProxy = 100
+	if got := c.paramRouterMaxTotalConnections(p).Value; got != "200" {
+		t.Errorf("paramRouterMaxTotalConnections by cpu: got %s, want 200", got)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Async replication
+// ---------------------------------------------------------------------------
+
+func TestBufferPoolLimitsByDBType(t *testing.T) {
+	cases := []struct {
+		dbtype  string
+		wantPct float64
+		wantMin float64
+	}{
+		{DbTypePXC, InnoDBPctValuePXC, MinLimitPXC},
+		{DbTypeGroupReplication, InnoDBPctValueGR, MinLimitGR},
+		{DbTypeAsync, InnoDBPctValueAsync, MinLimitAsync},
+	}
+	for _, tc := range cases {
+		if got := bufferPoolPctByDBType(tc.dbtype); got != tc.wantPct {
+			t.Errorf("bufferPoolPctByDBType(%s) = %.2f, want %.2f", tc.dbtype, got, tc.wantPct)
+		}
+		if got := minLimitByDBType(tc.dbtype); got != tc.wantMin {
+			t.Errorf("minLimitByDBType(%s) = %.2f, want %.2f", tc.dbtype, got, tc.wantMin)
+		}
+	}
+}
+
+func TestParamReplicaParallelWorkers_Async(t *testing.T) {
+	cases := []struct {
+		loadID int
+		want   string
+	}{
+		{LoadTypeMostlyReads, "4"},
+		{LoadTypeSomeWrites, "8"},
+		{LoadTypeEqualReadsWrites, "10"},
+		{LoadTypeHeavyWrites, "12"},
+	}
+	for _, tc := range cases {
+		c := newTestConfigurator(tc.loadID, DbTypeAsync, 100, 4000, 8*testGB)
+		p := Parameter{Default: "4", Max: 1024}
+		if got := c.paramReplicaParallelWorkers(p).Value; got != tc.want {
+			t.Errorf("loadID=%d paramReplicaParallelWorkers: got %s, want %s", tc.loadID, got, tc.want)
+		}
+	}
+
+	// pxc and group replication keep 2.5 workers per core whatever the load
+	c := newTestConfigurator(LoadTypeHeavyWrites, DbTypePXC, 100, 4000, 8*testGB)
+	if got := c.paramReplicaParallelWorkers(Parameter{Default: "4", Max: 1024}).Value; got != "10" {
+		t.Errorf("pxc paramReplicaParallelWorkers: got %s, want 10", got)
+	}
+
+	// only async is clamped to the parameter max
+	c = newTestConfigurator(LoadTypeHeavyWrites, DbTypeAsync, 100, 4000, 8*testGB)
+	if got := c.paramReplicaParallelWorkers(Parameter{Default: "4", Max: 8}).Value; got != "8" {
+		t.Errorf("async paramReplicaParallelWorkers: got %s, want 8", got)
+	}
+	c = newTestConfigurator(LoadTypeHeavyWrites, DbTypePXC, 100, 4000, 8*testGB)
+	if got := c.paramReplicaParallelWorkers(Parameter{Default: "4", Max: 8}).Value; got != "10" {
+		t.Errorf("pxc paramReplicaParallelWorkers: got %s, want 10", got)
+	}
+}
+
+func TestAsyncBinlogParameters(t *testing.T) {
+	c := newTestConfigurator(LoadTypeMostlyReads, DbTypeAsync, 100, 4000, 8*testGB)
+	if got := c.paramMaxBinlogSize(Parameter{}).Value; got != "268435456" {
+		t.Errorf("reads max_binlog_size: got %s, want 268435456", got)
+	}
+	c = newTestConfigurator(LoadTypeHeavyWrites, DbTypeAsync, 100, 4000, 8*testGB)
+	if got := c.paramMaxBinlogSize(Parameter{}).Value; got != "1073741824" {
+		t.Errorf("writes max_binlog_size: got %s, want 1073741824", got)
+	}
+
+	pending := Parameter{Default: "134217728"}
+	setWorkers := func(c *Configurator, workers string) {
+		c.families = map[string]Family{"mysql": {Groups: map[string]GroupObj{"configuration_replica": {Parameters: map[string]Parameter{
+			"replica_parallel_workers": {Value: workers},
+		}}}}}
+	}
+	// 12 workers × 64MB, within 5% of the mysql memory
+	c.reference.memoryMySQL = 32 * testGB
+	setWorkers(c, "12")
+	if got := c.paramReplicaPendingJobsSizeMax(pending).Value; got != strconv.Itoa(12*64*testMB) {
+		t.Errorf("replica_pending_jobs_size_max: got %s, want %d", got, 12*64*testMB)
+	}
+	// 5% of 8GB is below 12 × 64MB
+	c.reference.memoryMySQL = 8 * testGB
+	want := strconv.Itoa(int(c.reference.memoryMySQL * 0.05))
+	if got := c.paramReplicaPendingJobsSizeMax(pending).Value; got != want {
+		t.Errorf("replica_pending_jobs_size_max: got %s, want %s", got, want)
+	}
+	// never below the default
+	c.reference.memoryMySQL = 1 * testGB
+	if got := c.paramReplicaPendingJobsSizeMax(pending).Value; got != "134217728" {
+		t.Errorf("replica_pending_jobs_size_max: got %s, want the default", got)
+	}
+}
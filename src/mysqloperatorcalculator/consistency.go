@@ -0,0 +1,186 @@
+package mysqloperatorcalculator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Group replication consistency levels
+const (
+	ConsistencyEventual                = "EVENTUAL"
+	ConsistencyBeforeOnPrimaryFailover = "BEFORE_ON_PRIMARY_FAILOVER"
+	ConsistencyBefore                  = "BEFORE"
+	ConsistencyAfter                   = "AFTER"
+	ConsistencyBeforeAndAfter          = "BEFORE_AND_AFTER"
+)
+
+const (
+	// groupReplicationFlowControlDefault is the default certifier and applier threshold, transactions in the queue
+	groupReplicationFlowControlDefault = 25000
+	// groupReplicationTransactionMemoryShare is the share of the MySQL memory one transaction may take,
+	// every member holds it whole while it is certified and applied
+	groupReplicationTransactionMemoryShare = 0.05
+)
+
+var consistencyLevels = []string{ConsistencyEventual, ConsistencyBeforeOnPrimaryFailover, ConsistencyBefore, ConsistencyAfter, ConsistencyBeforeAndAfter}
+
+type ConsistencyReport struct {
+	Mode        string   `json:"mode"`
+	Consistency string   `json:"consistency"`
+	Warnings    []string `json:"warnings,omitempty"`
+}
+
+// consistencyParameters is the group replication profile, see groupProfile
+func consistencyParameters() map[string]map[string]Parameter {
+	return map[string]map[string]Parameter{
+		"configuration_groupReplication": {
+			"loose_group_replication_consistency":                      {"loose_group_replication_consistency", "configuration", "groupReplication", "EVENTUAL", "EVENTUAL", 0, 0, MySQLVersions{V8_0_46, V11_1_1}},
+			"loose_group_replication_flow_control_certifier_threshold": {"loose_group_replication_flow_control_certifier_threshold", "configuration", "groupReplication", "25000", "25000", 0, 2147483647, MySQLVersions{V8_0_46, V11_1_1}},
+			"loose_group_replication_flow_control_applier_threshold":   {"loose_group_replication_flow_control_applier_threshold", "configuration", "groupReplication", "25000", "25000", 0, 2147483647, MySQLVersions{V8_0_46, V11_1_1}},
+			"loose_group_replication_transaction_size_limit":           {"loose_group_replication_transaction_size_limit", "configuration", "groupReplication", "150000000", "150000000", 0, 2147483647, MySQLVersions{V8_0_46, V11_1_1}},
+		},
+	}
+}
+
+func validateConsistency(request ConfigurationRequest) error {
+	if request.Consistency == "" {
+		return nil
+	}
+	if request.DBType != DbTypeGroupReplication {
+		return fmt.Errorf("consistency only applies to DB Type %s", DbTypeGroupReplication)
+	}
+	for _, level := range consistencyLevels {
+		if request.Consistency == level {
+			return nil
+		}
+	}
+	return fmt.Errorf("Consistency %s is not correct. Supported levels are: %s", request.Consistency, strings.Join(consistencyLevels, ", "))
+}
+
+// groupProfile reports if the request gets the group replication profile
+func (r ConfigurationRequest) groupProfile() bool {
+	return r.DBType == DbTypeGroupReplication && (r.Mode != "" || r.Consistency != "")
+}
+
+// consistency returns the requested level, BEFORE_ON_PRIMARY_FAILOVER for single-primary and EVENTUAL for multi-primary when not set
+func (r ConfigurationRequest) consistency() string {
+	switch {
+	case r.Consistency != "":
+		return r.Consistency
+	case r.multiPrimary():
+		return ConsistencyEventual
+	default:
+		return ConsistencyBeforeOnPrimaryFailover
+	}
+}
+
+// applierWaits reports if transactions wait for the members to apply the backlog, reads with BEFORE, commits with AFTER
+func (r ConfigurationRequest) applierWaits() bool {
+	level := r.consistency()
+	return level == ConsistencyBefore || level == ConsistencyAfter || level == ConsistencyBeforeAndAfter
+}
+
+func (c *Configurator) getGroupReplicationConsistency() {
+	if !c.request.groupProfile() {
+		return
+	}
+	group := c.families[FamilyTypeMysql].Groups["configuration_groupReplication"]
+	c.traced(group, "loose_group_replication_consistency",
+		func(p Parameter) Parameter {
+			p.Value = c.request.consistency()
+			return p
+		}, "the requested level, BEFORE_ON_PRIMARY_FAILOVER for single-primary, EVENTUAL for multi-primary", traceConsistency, traceMode)
+	c.traced(group, "loose_group_replication_flow_control_certifier_threshold",
+		c.paramGroupReplicationCertifierThreshold, "by load type, the default for multi-primary", traceLoadID, traceMode)
+	c.traced(group, "loose_group_replication_flow_control_applier_threshold",
+		c.paramGroupReplicationApplierThreshold, "by load type, never above the default when transactions wait for the applier", traceLoadID, traceConsistency)
+	c.traced(group, "loose_group_replication_transaction_size_limit",
+		c.paramGroupReplicationTransactionSizeLimit, "by load type, max for analytics, at most 5% of the mysql memory", traceLoadID, traceMemoryMySQL)
+	c.families[FamilyTypeMysql].Groups["configuration_groupReplication"] = group
+}
+
+// flowControlThreshold returns the queued transactions flow control lets a member hold: write heavy loads commit in bursts
+func (c *Configurator) flowControlThreshold() string {
+	return c.loadValues([4]string{"25000", "25000", "50000", "100000"})
+}
+
+// paramGroupReplicationCertifierThreshold keeps the default with several writers, a longer certification queue is more conflicts
+func (c *Configurator) paramGroupReplicationCertifierThreshold(parameter Parameter) Parameter {
+	if c.request.multiPrimary() {
+		c.branch("multi-primary")
+		parameter.Value = parameter.Default
+		return parameter
+	}
+	parameter.Value = c.flowControlThreshold()
+	return parameter
+}
+
+// paramGroupReplicationApplierThreshold keeps the applier queue short when reads or commits wait for it
+func (c *Configurator) paramGroupReplicationApplierThreshold(parameter Parameter) Parameter {
+	threshold, _ := strconv.Atoi(c.flowControlThreshold())
+	if c.request.applierWaits() && threshold > groupReplicationFlowControlDefault {
+		threshold = groupReplicationFlowControlDefault
+		c.clamped("default", threshold)
+	}
+	parameter.Value = strconv.Itoa(threshold)
+	return parameter
+}
+
+// paramGroupReplicationTransactionSizeLimit lets write heavy and analytics loads write larger transactions,
+// within groupReplicationTransactionMemoryShare of the memory
+func (c *Configurator) paramGroupReplicationTransactionSizeLimit(parameter Parameter) Parameter {
+	limit, _ := strconv.ParseInt(c.loadValues([4]string{"150000000", "150000000", "200000000", "300000000"}), 10, 64)
+	if c.reference.loadID == LoadTypeAnalytics {
+		limit = int64(parameter.Max)
+	}
+	if byMemory := int64(c.reference.memoryMySQL * groupReplicationTransactionMemoryShare); limit > byMemory {
+		limit = byMemory
+		c.clamped("5% of the mysql memory", int(limit))
+	}
+	parameter.Value = strconv.FormatInt(limit, 10)
+	return parameter
+}
+
+// getConsistencyReport warns about the consistency levels that do not match the mode, the network or the load.
+// The level and the single leader are the values set, pinned ones included
+func (c *Configurator) getConsistencyReport() *ConsistencyReport {
+	mode := c.request.Mode
+	if mode == "" {
+		mode = ModeSinglePrimary
+	}
+	parameters := c.families[FamilyTypeMysql].Groups["configuration_groupReplication"].Parameters
+	level := strings.ToUpper(parameters["loose_group_replication_consistency"].Value)
+	report := &ConsistencyReport{Mode: mode, Consistency: level}
+	warn := func(format string, a ...interface{}) {
+		report.Warnings = append(report.Warnings, fmt.Sprintf(format, a...))
+	}
+
+	if c.request.multiPrimary() && level == ConsistencyBeforeOnPrimaryFailover {
+		warn("%s has no primary to fail over in %s, it behaves as %s", level, ModeMultiPrimary, ConsistencyEventual)
+	}
+	if c.request.multiPrimary() && strings.EqualFold(parameters["loose_group_replication_paxos_single_leader"].Value, "ON") {
+		warn("%s with paxos_single_leader ON: a multi-primary group has no single leader, every member still runs consensus for its writes", ModeMultiPrimary)
+	}
+	if c.request.multiPrimary() && c.reference.loadID == LoadTypeHeavyWrites {
+		warn("%s with a write heavy load: writes to the same rows on several members fail certification and are rolled back", ModeMultiPrimary)
+	}
+	if (level == ConsistencyAfter || level == ConsistencyBeforeAndAfter) && c.reference.loadID == LoadTypeHeavyWrites {
+		warn("%s with a write heavy load: every commit waits for all the members to apply it, the slowest member sets the write rate", level)
+	}
+	if (level == ConsistencyBefore || level == ConsistencyBeforeAndAfter) && (c.reference.loadID == LoadTypeMostlyReads || c.reference.loadID == LoadTypeAnalytics) {
+		warn("%s with a read load: every read waits for the member to apply the group backlog, AFTER on the writes is cheaper", level)
+	}
+	if n := c.request.Network; n != nil && n.wan() && level != ConsistencyEventual && level != ConsistencyBeforeOnPrimaryFailover {
+		warn("%s on a WAN: each synchronized transaction adds at least one more round trip, %.1fms", level, n.RttMs)
+	}
+	return report
+}
+
+func (r *ConsistencyReport) text() string {
+	text := fmt.Sprintf("\nGroup replication %s, consistency %s\n", r.Mode, r.Consistency)
+	for _, warning := range r.Warnings {
+		text += "!!!! " + warning + " !!!!\n"
+	}
+	return text
+}
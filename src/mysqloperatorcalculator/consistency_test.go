@@ -0,0 +1,116 @@
+package mysqloperatorcalculator
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestConsistency_Calculate(t *testing.T) {
+	groupReplication := func(req ConfigurationRequest) (ResponseMessage, map[string]Parameter) {
+		t.Helper()
+		err, msg, families := runCalculate(req)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return msg, families[FamilyTypeMysql].Groups["configuration_groupReplication"].Parameters
+	}
+	warnings := func(msg ResponseMessage) []string {
+		return messageReport[*ConsistencyReport](msg, ReportConsistency).Warnings
+	}
+
+	_, gr := groupReplication(makeRequest(DbTypeGroupReplication, 4, LoadTypeHeavyWrites, 100))
+	if _, ok := gr["loose_group_replication_consistency"]; ok {
+		t.Error("the consistency profile must only be set with a mode or a consistency")
+	}
+
+	req := makeRequest(DbTypeGroupReplication, 4, LoadTypeHeavyWrites, 100)
+	req.Mode = ModeSinglePrimary
+	msg, gr := groupReplication(req)
+	for name, want := range map[string]string{
+		"loose_group_replication_consistency":                      ConsistencyBeforeOnPrimaryFailover,
+		"loose_group_replication_flow_control_certifier_threshold": "100000",
+		"loose_group_replication_flow_control_applier_threshold":   "100000",
+		"loose_group_replication_transaction_size_limit":           "300000000",
+	} {
+		if v := gr[name].Value; v != want {
+			t.Errorf("single-primary heavy writes: %s = %s, want %s", name, v, want)
+		}
+	}
+	if report := messageReport[*ConsistencyReport](msg, ReportConsistency); report == nil || len(report.Warnings) != 0 {
+		t.Errorf("single-primary heavy writes must not warn: %+v", report)
+	}
+
+	// Multi-primary keeps the certification queue short and is warned about the conflicts
+	req.Mode = ModeMultiPrimary
+	req.Consistency = ConsistencyBeforeOnPrimaryFailover
+	msg, gr = groupReplication(req)
+	if v := gr["loose_group_replication_flow_control_certifier_threshold"].Value; v != "25000" {
+		t.Errorf("multi-primary certifier threshold = %s, want the default", v)
+	}
+	if len(warnings(msg)) != 2 || !strings.Contains(msg.MText, "no primary to fail over") {
+		t.Errorf("multi-primary with a failover level and heavy writes must warn twice: %v", warnings(msg))
+	}
+
+	// Reads waiting for the applier keep its queue at the default
+	req = makeRequest(DbTypeGroupReplication, 4, LoadTypeHeavyWrites, 100)
+	req.Consistency = ConsistencyAfter
+	msg, gr = groupReplication(req)
+	if v := gr["loose_group_replication_flow_control_applier_threshold"].Value; v != "25000" {
+		t.Errorf("AFTER applier threshold = %s, want the default", v)
+	}
+	if v := gr["loose_group_replication_consistency"].Value; v != ConsistencyAfter || len(warnings(msg)) != 1 {
+		t.Errorf("AFTER with heavy writes = %s, warnings %v", v, warnings(msg))
+	}
+	req = makeRequest(DbTypeGroupReplication, 4, LoadTypeMostlyReads, 100)
+	req.Consistency = ConsistencyBefore
+	req.Network = &Network{RttMs: 30}
+	if msg, _ = groupReplication(req); len(warnings(msg)) != 2 {
+		t.Errorf("BEFORE on reads over a WAN must warn twice: %v", warnings(msg))
+	}
+
+	// The transaction size limit follows the memory of small pods
+	req = makeRequest(DbTypeGroupReplication, 1, LoadTypeHeavyWrites, 50)
+	req.Mode = ModeSinglePrimary
+	msg, gr = groupReplication(req)
+	want := strconv.FormatInt(int64(float64(msg.Breakdown.Memory.Mysql.Allocated)*groupReplicationTransactionMemoryShare), 10)
+	if v := gr["loose_group_replication_transaction_size_limit"].Value; v != want {
+		t.Errorf("transaction_size_limit = %s, want %s, the share of the mysql memory of the smallest pod", v, want)
+	}
+
+	for name, tc := range map[string]struct {
+		dbtype      string
+		consistency string
+	}{
+		"pxc":     {DbTypePXC, ConsistencyAfter},
+		"unknown": {DbTypeGroupReplication, "STRONG"},
+		"case":    {DbTypeGroupReplication, "after"},
+	} {
+		req := makeRequest(tc.dbtype, 4, LoadTypeSomeWrites, 100)
+		req.Consistency = tc.consistency
+		if err, _, _ := runCalculate(req); err == nil {
+			t.Errorf("%s: expected a consistency error", name)
+		}
+	}
+
+	// Overrides of the enumerated variables are checked, mysqld accepts them in any case
+	req = makeRequest(DbTypeGroupReplication, 4, LoadTypeSomeWrites, 100)
+	req.Mode = ModeSinglePrimary
+	req.Overrides = map[string]string{"loose_group_replication_consistency": "BOGUS"}
+	if err, _, _ := runCalculate(req); err == nil {
+		t.Error("expected an error for an unknown consistency override")
+	}
+	req.Overrides = map[string]string{"loose_group_replication_consistency": "after"}
+	msg, _ = groupReplication(req)
+	if report := messageReport[*ConsistencyReport](msg, ReportConsistency); report == nil || report.Consistency != ConsistencyAfter {
+		t.Errorf("the pinned consistency must be reported: %+v", report)
+	}
+
+	// A leader pinned on a multi-primary group is warned about
+	req = makeRequest(DbTypeGroupReplication, 4, LoadTypeMostlyReads, 100)
+	req.Mode = ModeMultiPrimary
+	req.Overrides = map[string]string{"loose_group_replication_paxos_single_leader": "ON"}
+	if msg, _ = groupReplication(req); !strings.Contains(msg.MText, "paxos_single_leader ON") {
+		t.Errorf("multi-primary with paxos_single_leader ON must warn: %v", warnings(msg))
+	}
+}
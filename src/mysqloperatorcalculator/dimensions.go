@@ -0,0 +1,138 @@
+package mysqloperatorcalculator
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	dimensionCatalogMu sync.RWMutex
+	dimensionCatalog   []Dimension
+)
+
+// dimensionCatalogFile is the on-disk layout, memory values accept the same units as the request (e.g. "8GB", "512MB")
+type dimensionCatalogFile struct {
+	Dimensions []struct {
+		Id          int    `yaml:"id" json:"id"`
+		Name        string `yaml:"name" json:"name"`
+		Cpu         int    `yaml:"cpu" json:"cpu"`
+		Memory      string `yaml:"memory" json:"memory"`
+		MysqlCpu    int    `yaml:"mysqlCpu" json:"mysqlCpu"`
+		ProxyCpu    int    `yaml:"proxyCpu" json:"proxyCpu"`
+		PmmCpu      int    `yaml:"pmmCpu" json:"pmmCpu"`
+		MysqlMemory string `yaml:"mysqlMemory" json:"mysqlMemory"`
+		ProxyMemory string `yaml:"proxyMemory" json:"proxyMemory"`
+		PmmMemory   string `yaml:"pmmMemory" json:"pmmMemory"`
+	} `yaml:"dimensions" json:"dimensions"`
+}
+
+// LoadDimensionCatalog reads and validates a dimension catalog from a .json, .yaml or .yml file.
+// The result can be installed with SetDimensionCatalog
+func LoadDimensionCatalog(path string) ([]Dimension, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var file dimensionCatalogFile
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		err = json.Unmarshal(data, &file)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &file)
+	default:
+		return nil, fmt.Errorf("dimension catalog %s: unsupported extension, use .json, .yaml or .yml", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("dimension catalog %s: %v", path, err)
+	}
+
+	dimensions := make([]Dimension, 0, len(file.Dimensions))
+	for _, entry := range file.Dimensions {
+		var dim Dimension
+		memories := []*float64{&dim.MemoryBytes, &dim.MysqlMemory, &dim.ProxyMemory, &dim.PmmMemory}
+		for i, human := range []string{entry.Memory, entry.MysqlMemory, entry.ProxyMemory, entry.PmmMemory} {
+			if *memories[i], err = dim.ConvertMemoryToBytes(human); err != nil {
+				return nil, fmt.Errorf("dimension catalog %s: dimension %d: %v", path, entry.Id, err)
+			}
+		}
+		dim.Id = entry.Id
+		dim.Name = entry.Name
+		dim.Cpu = entry.Cpu
+		dim.Memory = entry.Memory
+		dim.MysqlCpu = entry.MysqlCpu
+		dim.ProxyCpu = entry.ProxyCpu
+		dim.PmmCpu = entry.PmmCpu
+		dimensions = append(dimensions, dim)
+	}
+
+	if err := ValidateDimensionCatalog(dimensions); err != nil {
+		return nil, fmt.Errorf("dimension catalog %s: %v", path, err)
+	}
+	return dimensions, nil
+}
+
+// ValidateDimensionCatalog checks that ids are unique, CPU and memory grow with each dimension
+// and that the mysql, proxy and pmm shares fit in the dimension totals
+func ValidateDimensionCatalog(dimensions []Dimension) error {
+	if len(dimensions) == 0 {
+		return errors.New("no dimensions defined")
+	}
+
+	ids := make(map[int]bool, len(dimensions))
+	for i, dim := range dimensions {
+		switch {
+		case dim.Id <= 0 || dim.Id == DimensionOpen || dim.Id == ConnectionDimension:
+			return fmt.Errorf("dimension %d: id must be positive and different from %d and %d", dim.Id, ConnectionDimension, DimensionOpen)
+		case ids[dim.Id]:
+			return fmt.Errorf("dimension %d: duplicated id", dim.Id)
+		case dim.Cpu <= 0 || dim.MemoryBytes <= 0:
+			return fmt.Errorf("dimension %d: cpu and memory must be greater than 0", dim.Id)
+		case dim.MysqlCpu <= 0 || dim.ProxyCpu <= 0 || dim.PmmCpu <= 0:
+			return fmt.Errorf("dimension %d: mysqlCpu, proxyCpu and pmmCpu must be greater than 0", dim.Id)
+		case dim.MysqlMemory <= 0 || dim.ProxyMemory <= 0 || dim.PmmMemory <= 0:
+			return fmt.Errorf("dimension %d: mysqlMemory, proxyMemory and pmmMemory must be greater than 0", dim.Id)
+		case dim.MysqlCpu+dim.ProxyCpu+dim.PmmCpu > dim.Cpu:
+			return fmt.Errorf("dimension %d: cpu shares %d exceed the total %d", dim.Id, dim.MysqlCpu+dim.ProxyCpu+dim.PmmCpu, dim.Cpu)
+		case dim.MysqlMemory+dim.ProxyMemory+dim.PmmMemory > dim.MemoryBytes:
+			return fmt.Errorf("dimension %d: memory shares %.0f exceed the total %.0f", dim.Id, dim.MysqlMemory+dim.ProxyMemory+dim.PmmMemory, dim.MemoryBytes)
+		}
+		if i > 0 {
+			prev := dimensions[i-1]
+			if dim.Cpu <= prev.Cpu || dim.MemoryBytes <= prev.MemoryBytes {
+				return fmt.Errorf("dimension %d: cpu and memory must be greater than dimension %d", dim.Id, prev.Id)
+			}
+		}
+		ids[dim.Id] = true
+	}
+	return nil
+}
+
+// SetDimensionCatalog validates and installs the dimensions used by Configuration.Init in place of
+// the built-in table. A nil catalog restores the built-in table
+func SetDimensionCatalog(dimensions []Dimension) error {
+	if dimensions != nil {
+		if err := ValidateDimensionCatalog(dimensions); err != nil {
+			return err
+		}
+	}
+
+	dimensionCatalogMu.Lock()
+	defer dimensionCatalogMu.Unlock()
+	dimensionCatalog = append([]Dimension(nil), dimensions...)
+	return nil
+}
+
+// getDimensionCatalog returns a copy of the installed catalog, nil when the built-in table is in use
+func getDimensionCatalog() []Dimension {
+	dimensionCatalogMu.RLock()
+	defer dimensionCatalogMu.RUnlock()
+	return append([]Dimension(nil), dimensionCatalog...)
+}
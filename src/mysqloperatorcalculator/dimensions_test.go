@@ -0,0 +1,115 @@
+package mysqloperatorcalculator
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// useDimensionCatalog installs the testdata catalog for the duration of the test.
+func useDimensionCatalog(t *testing.T) []Dimension {
+	t.Helper()
+	catalog, err := LoadDimensionCatalog(filepath.Join("testdata", "dimensions", "catalog.yaml"))
+	if err != nil {
+		t.Fatalf("LoadDimensionCatalog: %v", err)
+	}
+	if err := SetDimensionCatalog(catalog); err != nil {
+		t.Fatalf("SetDimensionCatalog: %v", err)
+	}
+	t.Cleanup(func() { SetDimensionCatalog(nil) })
+	return catalog
+}
+
+func TestLoadDimensionCatalog_YAMLAndJSON(t *testing.T) {
+	fromYAML, err := LoadDimensionCatalog(filepath.Join("testdata", "dimensions", "catalog.yaml"))
+	if err != nil {
+		t.Fatalf("yaml: %v", err)
+	}
+	fromJSON, err := LoadDimensionCatalog(filepath.Join("testdata", "dimensions", "catalog.json"))
+	if err != nil {
+		t.Fatalf("json: %v", err)
+	}
+	if !reflect.DeepEqual(fromYAML, fromJSON) {
+		t.Errorf("yaml and json catalogs differ:\n%+v\n%+v", fromYAML, fromJSON)
+	}
+	if len(fromYAML) != 3 || fromYAML[1].Name != "pool-medium" || fromYAML[1].MemoryBytes != 24*testGB || fromYAML[1].ProxyMemory != testGB {
+		t.Errorf("unexpected catalog content: %+v", fromYAML)
+	}
+}
+
+func TestLoadDimensionCatalog_Invalid(t *testing.T) {
+	if _, err := LoadDimensionCatalog(filepath.Join("testdata", "dimensions", "missing.yaml")); err == nil {
+		t.Error("expected error for a missing file, got nil")
+	}
+	if _, err := LoadDimensionCatalog(filepath.Join("testdata", "dimensions", "catalog.txt")); err == nil {
+		t.Error("expected error for an unsupported extension, got nil")
+	}
+}
+
+func TestValidateDimensionCatalog(t *testing.T) {
+	valid := func() []Dimension {
+		return []Dimension{
+			{1, "a", 2000, "4GB", 4 * testGB, 1600, 250, 150, 3 * testGB, 512 * testMB, 256 * testMB},
+			{2, "b", 4000, "8GB", 8 * testGB, 3400, 400, 200, 7 * testGB, 512 * testMB, 256 * testMB},
+		}
+	}
+	if err := ValidateDimensionCatalog(valid()); err != nil {
+		t.Fatalf("valid catalog rejected: %v", err)
+	}
+
+	cases := map[string]func([]Dimension) []Dimension{
+		"empty":              func(d []Dimension) []Dimension { return nil },
+		"duplicated id":      func(d []Dimension) []Dimension { d[1].Id = 1; return d },
+		"reserved id":        func(d []Dimension) []Dimension { d[1].Id = DimensionOpen; return d },
+		"cpu not increasing": func(d []Dimension) []Dimension { d[1].Cpu = 2000; return d },
+		"memory decreasing":  func(d []Dimension) []Dimension { d[1].MemoryBytes = 2 * testGB; return d },
+		"cpu shares":         func(d []Dimension) []Dimension { d[0].MysqlCpu = 1700; return d },
+		"memory shares":      func(d []Dimension) []Dimension { d[0].MysqlMemory = 4 * testGB; return d },
+		"missing share":      func(d []Dimension) []Dimension { d[0].PmmCpu = 0; return d },
+	}
+	for name, mutate := range cases {
+		if err := ValidateDimensionCatalog(mutate(valid())); err == nil {
+			t.Errorf("%s: expected error, got nil", name)
+		}
+	}
+}
+
+func TestSetDimensionCatalog_ReplacesBuiltinTable(t *testing.T) {
+	catalog := useDimensionCatalog(t)
+
+	var conf Configuration
+	conf.Init()
+	if len(conf.Dimension) != len(catalog)+2 {
+		t.Fatalf("expected %d catalog dimensions plus the open and connection sentinels, got %d", len(catalog), len(conf.Dimension))
+	}
+	if dim := conf.GetDimensionByID(2); dim.Name != "pool-medium" {
+		t.Errorf("GetDimensionByID(2) = %q, want pool-medium", dim.Name)
+	}
+
+	scaled, err := conf.ScaleDimension(conf.GetDimensionByID(1))
+	if err != nil {
+		t.Fatalf("ScaleDimension: %v", err)
+	}
+	if scaled.Cpu != catalog[0].Cpu+CPUIncrement {
+		t.Errorf("scaled cpu = %d, want %d", scaled.Cpu, catalog[0].Cpu+CPUIncrement)
+	}
+
+	free := conf.getDimensionForFreeCalculation(Dimension{Cpu: 8000, MemoryBytes: 30 * testGB})
+	if free.Name != "pool-medium" {
+		t.Errorf("getDimensionForFreeCalculation = %q, want pool-medium", free.Name)
+	}
+
+	req := makeRequest(DbTypePXC, 2, LoadTypeSomeWrites, 100)
+	if err, _, families := runCalculate(req); err != nil || len(families) == 0 {
+		t.Errorf("calculation against the catalog failed: %v", err)
+	}
+
+	if err := SetDimensionCatalog([]Dimension{{Id: 1}}); err == nil {
+		t.Error("expected SetDimensionCatalog to reject an invalid catalog")
+	}
+	SetDimensionCatalog(nil)
+	conf.Init()
+	if dim := conf.GetDimensionByID(1); dim.Name != "XSmall" {
+		t.Errorf("built-in table not restored, dimension 1 = %q", dim.Name)
+	}
+}
@@ -0,0 +1,174 @@
+package mysqloperatorcalculator
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Inputs a rule can read, the names of the references they come from
+const (
+	traceLoadID           = "loadID"
+	traceLoadFactor       = "loadFactor"
+	traceWriteRatio       = "writeRatio"
+	traceConnections      = "connections"
+	traceCpus             = "cpus"
+	traceCpusMySQL        = "cpusMySQL"
+	traceCpusProxy        = "cpusProxy"
+	traceMemoryMySQL      = "memoryMySQL"
+	traceMemoryProxy      = "memoryProxy"
+	traceMemoryLeftover   = "memoryLeftover"
+	traceInnoDBbpSize     = "innoDBbpSize"
+	traceIdealBufferPool  = "idealBufferPoolDIm"
+	traceInnodbRedoLogDim = "innodbRedoLogDim"
+	traceGcache           = "gcache"
+	traceGcacheLoad       = "gcacheLoad"
+	traceGcscacheLoad     = "gcscacheLoad"
+	traceDBType           = "dbtype"
+	traceNodes            = "nodes"
+	traceIops             = "iops"
+	traceMode             = "mode"
+	traceRtt              = "rttMs"
+	traceConsistency      = "consistency"
+	traceTmpDiskPct       = "tmpDiskPct"
+)
+
+type ParameterTrace struct {
+	Rule     string            `json:"rule"`
+	Inputs   map[string]string `json:"inputs,omitempty"`
+	Branch   string            `json:"branch,omitempty"`
+	Clamped  string            `json:"clamped,omitempty"`
+	Replaced string            `json:"replaced"`
+	// Pinned is set for overridden parameters with or without explain
+	Pinned bool `json:"-"`
+	// PinnedOptions are the pinned provider options of wsrep-provider-options
+	PinnedOptions []string `json:"-"`
+}
+
+// traced sets the parameter key of the group with set and, when explain is on, records its trace with the inputs
+// read before set ran. A pinned value always wins over what set calculated
+func (c *Configurator) traced(group GroupObj, key string, set func(Parameter) Parameter, rule string, inputs ...string) {
+	var snapshot map[string]string
+	if c.request.Explain {
+		snapshot = c.traceInputs(inputs)
+		c.traceBranch, c.traceClamp = "", ""
+	}
+	c.record(group, key, set(group.Parameters[key]), rule, snapshot)
+}
+
+// explained records the trace of a parameter set in place, inputs are read as they are now
+func (c *Configurator) explained(group GroupObj, key string, parameter Parameter, rule string, inputs ...string) {
+	var snapshot map[string]string
+	if c.request.Explain {
+		snapshot = c.traceInputs(inputs)
+	}
+	c.record(group, key, parameter, rule, snapshot)
+}
+
+// record sets the parameter and its trace, a parameter pinned by applyOverrides keeps the pinned value
+func (c *Configurator) record(group GroupObj, key string, parameter Parameter, rule string, inputs map[string]string) {
+	pinned := group.IsPinned(key)
+	group.Parameters[key] = parameter
+	if c.request.Explain {
+		group.Traces[key] = &ParameterTrace{
+			Rule:     rule,
+			Inputs:   inputs,
+			Branch:   c.traceBranch,
+			Clamped:  c.traceClamp,
+			Replaced: parameter.Default,
+		}
+	}
+	c.traceBranch, c.traceClamp = "", ""
+	if pinned {
+		c.pin(group, key)
+	}
+}
+
+// branch records the branch a rule took for the parameter being traced
+func (c *Configurator) branch(format string, args ...interface{}) {
+	if c.request.Explain {
+		c.traceBranch = fmt.Sprintf(format, args...)
+	}
+}
+
+// clamped records that the value was forced to a bound (min, max, default or a hard limit)
+func (c *Configurator) clamped(bound string, limit int) {
+	if c.request.Explain {
+		c.traceClamp = bound + " " + strconv.Itoa(limit)
+	}
+}
+
+func (c *Configurator) traceInputs(names []string) map[string]string {
+	if len(names) == 0 {
+		return nil
+	}
+	inputs := make(map[string]string, len(names))
+	for _, name := range names {
+		inputs[name] = c.traceInput(name)
+		// the load type only picks the bucket, the values come from the share of writes
+		if name == traceLoadID && c.reference.byRatio {
+			inputs[traceWriteRatio] = strconv.FormatFloat(c.reference.writeRatio, 'f', 4, 64)
+		}
+	}
+	return inputs
+}
+
+func (c *Configurator) traceInput(name string) string {
+	r := c.reference
+	switch name {
+	case traceLoadID:
+		return strconv.Itoa(r.loadID)
+	case traceLoadFactor:
+		return strconv.FormatFloat(float64(r.loadFactor), 'f', 4, 32)
+	case traceConnections:
+		return strconv.Itoa(r.connections)
+	case traceCpus:
+		return strconv.Itoa(r.cpus)
+	case traceCpusMySQL:
+		return strconv.FormatFloat(r.cpusMySQL, 'f', 0, 64)
+	case traceCpusProxy:
+		return strconv.FormatFloat(r.cpusProxy, 'f', 0, 64)
+	case traceMemoryMySQL:
+		return strconv.FormatFloat(r.memoryMySQL, 'f', 0, 64)
+	case traceMemoryProxy:
+		return strconv.FormatFloat(r.memoryProxy, 'f', 0, 64)
+	case traceMemoryLeftover:
+		return strconv.FormatInt(r.memoryLeftover, 10)
+	case traceInnoDBbpSize:
+		return strconv.FormatInt(r.innoDBbpSize, 10)
+	case traceIdealBufferPool:
+		return strconv.FormatInt(r.idealBufferPoolDIm, 10)
+	case traceInnodbRedoLogDim:
+		return strconv.FormatInt(r.innodbRedoLogDim, 10)
+	case traceGcache:
+		return strconv.FormatInt(r.gcache, 10)
+	case traceGcacheLoad:
+		return strconv.FormatFloat(r.gcacheLoad, 'f', 2, 64)
+	case traceGcscacheLoad:
+		return strconv.FormatFloat(r.gcscacheLoad, 'f', 2, 64)
+	case traceDBType:
+		return c.request.DBType
+	case traceNodes:
+		return strconv.Itoa(c.request.clusterNodes())
+	case traceMode:
+		if c.request.Mode == "" {
+			return ModeSinglePrimary
+		}
+		return c.request.Mode
+	case traceConsistency:
+		return c.request.consistency()
+	case traceRtt:
+		if c.request.Network == nil {
+			return "0"
+		}
+		return strconv.FormatFloat(c.request.Network.RttMs, 'f', 1, 64)
+	case traceIops:
+		return strconv.FormatInt(c.storageIops(), 10)
+	case traceTmpDiskPct:
+		if c.request.StatusProfile == nil {
+			return "0"
+		}
+		return strconv.FormatFloat(c.request.StatusProfile.TmpDiskPct, 'f', 4, 64)
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,108 @@
+package mysqloperatorcalculator
+
+import (
+	"strings"
+	"testing"
+)
+
+// newTestGroup returns a group of parameters with the traces map the Configurator sets up in Init.
+func newTestGroup(parameters map[string]Parameter) GroupObj {
+	return GroupObj{Parameters: parameters, Traces: make(map[string]*ParameterTrace)}
+}
+
+func TestTraced_RecordsInputsBranchAndClamp(t *testing.T) {
+	c := newTestConfigurator(LoadTypeSomeWrites, DbTypeGroupReplication, 5000, 2000, 4*testGB)
+	c.request.Explain = true
+	c.reference.memoryProxy = 200 * testMB
+	group := newTestGroup(map[string]Parameter{
+		"thread_cache_size":           {Default: "10", Max: 1000},
+		"max_idle_server_connections": {Max: 65535},
+	})
+
+	c.traced(group, "thread_cache_size", c.paramServerThreadCacheSize, "requested connections", traceConnections)
+	trace := group.Traces["thread_cache_size"]
+	if trace == nil {
+		t.Fatal("expected a trace")
+	}
+	if trace.Replaced != "10" || trace.Inputs[traceConnections] != "5000" || trace.Clamped != "max 1000" {
+		t.Errorf("unexpected trace %+v", trace)
+	}
+
+	// the clamp of a parameter must not leak into the next one
+	c.traced(group, "max_idle_server_connections", c.paramRouterMaxIdleServerConnections, "pool", traceConnections, traceMemoryProxy)
+	if trace = group.Traces["max_idle_server_connections"]; trace.Clamped != "" || trace.Branch != "limited by the proxy memory" {
+		t.Errorf("unexpected trace %+v", trace)
+	}
+}
+
+func TestTraced_OffByDefault(t *testing.T) {
+	c := newTestConfigurator(LoadTypeSomeWrites, DbTypeGroupReplication, 5000, 2000, 4*testGB)
+	group := newTestGroup(map[string]Parameter{"thread_cache_size": {Max: 1000}})
+	c.traced(group, "thread_cache_size", c.paramServerThreadCacheSize, "requested connections", traceConnections)
+	if p := group.Parameters["thread_cache_size"]; len(group.Traces) != 0 || p.Value != "1000" {
+		t.Errorf("expected no trace and value 1000, got %+v %+v", p, group.Traces)
+	}
+}
+
+// Every parameter the Configurator sets must carry its trace, and only when explain is requested
+func TestExplain_Families(t *testing.T) {
+	for _, dbtype := range []string{DbTypePXC, DbTypeGroupReplication} {
+		t.Run(dbtype, func(t *testing.T) {
+			req := makeRequest(dbtype, 4, LoadTypeSomeWrites, 200)
+			req.Explain = true
+			err, msg, families := runCalculate(req)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			traced := map[string][]string{
+				"configuration_connection": {"join_buffer_size", "read_rnd_buffer_size", "sort_buffer_size"},
+				"configuration_innodb":     {"innodb_buffer_pool_size", "innodb_buffer_pool_instances", "innodb_redo_log_capacity", "innodb_purge_threads"},
+				"configuration_server":     {"max_connections", "thread_cache_size"},
+				"resources":                {"request_memory", "limit_cpu"},
+			}
+			if dbtype == DbTypePXC {
+				traced["configuration_galera"] = []string{"wsrep-provider-options", "wsrep_trx_fragment_size"}
+			}
+			for group, names := range traced {
+				for _, name := range names {
+					g := families[FamilyTypeMysql].Groups[group]
+					trace := g.Traces[name]
+					if trace == nil || trace.Rule == "" || trace.Replaced != g.Parameters[name].Default {
+						t.Errorf("%s.%s: unexpected trace %+v", group, name, trace)
+					}
+				}
+			}
+			server := families[FamilyTypeMysql].Groups["configuration_server"]
+			if trace := server.Traces["max_connections"]; trace.Inputs[traceConnections] != "200" || server.Parameters["max_connections"].Value != "202" {
+				t.Errorf("max_connections = %s, trace %+v, want 200 connections plus the admin slots", server.Parameters["max_connections"].Value, trace)
+			}
+			innodb := families[FamilyTypeMysql].Groups["configuration_innodb"].Traces
+			if bp := innodb["innodb_buffer_pool_size"]; bp != nil && (bp.Inputs[traceInnoDBbpSize] == "" || bp.Branch == "") {
+				t.Errorf("buffer pool trace misses inputs or branch: %+v", bp)
+			}
+			// the redo log parameters are set one after the other, each keeps its own branch
+			for _, name := range []string{"innodb_redo_log_capacity", "innodb_log_files_in_group", "innodb_log_file_size"} {
+				if trace := innodb[name]; trace == nil || trace.Branch == "" {
+					t.Errorf("%s: trace misses its branch: %+v", name, trace)
+				}
+			}
+
+			var moc MysqlOperatorCalculator
+			b, err := moc.GetJSONOutput(msg, req, families)
+			if err != nil || !strings.Contains(b.String(), `"explain": {`) {
+				t.Errorf("JSON output must carry the traces, err=%v", err)
+			}
+		})
+	}
+
+	req := makeRequest(DbTypePXC, 4, LoadTypeSomeWrites, 200)
+	err, msg, families := runCalculate(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var moc MysqlOperatorCalculator
+	if b, _ := moc.GetJSONOutput(msg, req, families); strings.Contains(b.String(), `"explain"`) {
+		t.Error("JSON output must not carry traces unless explain is requested")
+	}
+}
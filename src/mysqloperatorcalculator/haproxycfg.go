@@ -0,0 +1,87 @@
+package mysqloperatorcalculator
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// haproxyBackends are the backend names the operators use for the writer nodes
+var haproxyBackends = map[string]string{
+	DbTypePXC:              "galera-nodes",
+	DbTypeGroupReplication: "mysql-primary",
+	DbTypeAsync:            "mysql-primary",
+}
+
+// GetHAProxyCfgOutput renders the proxy family as haproxy.cfg global/defaults/backend stanzas
+func (moc *MysqlOperatorCalculator) GetHAProxyCfgOutput(message ResponseMessage, request ConfigurationRequest, families map[string]Family) (bytes.Buffer, error) {
+	var b bytes.Buffer
+
+	cfg, err := haproxyCfg(request, families)
+	if err != nil {
+		return b, err
+	}
+	fmt.Fprintf(&b, "# haproxy.cfg generated by mysqloperatorcalculator %s\n", VERSION)
+	fmt.Fprintf(&b, "# message: %d %s\n", message.MType, message.MName)
+	b.Write(cfg.Bytes())
+	return b, nil
+}
+
+// haproxyCfg returns the stanzas of the haproxyConfig group. The backend caps every server at the
+// max_connections computed for the mysql nodes, with the share of a lost member, so HAProxy queues the excess
+func haproxyCfg(request ConfigurationRequest, families map[string]Family) (bytes.Buffer, error) {
+	var b bytes.Buffer
+
+	proxy, ok := families[FamilyTypeProxy]
+	if !ok {
+		return b, errors.New("no proxy configuration to render, see message for details")
+	}
+	haproxy, ok := proxy.Groups[GroupNameHAProxy]
+	if !ok {
+		return b, fmt.Errorf("proxy family %s has no %s group", proxy.Name, GroupNameHAProxy)
+	}
+	backend, ok := haproxyBackends[request.DBType]
+	if !ok {
+		return b, fmt.Errorf("no HAProxy backend defined for dbtype %q", request.DBType)
+	}
+
+	// Every backend server accepts what mysqld accepts minus the slots reserved for administration,
+	// × nodes / (nodes - 1): after a failover the surviving servers take the clients of the lost one
+	serverMaxConn := 0
+	if maxConnections, err := strconv.Atoi(families[FamilyTypeMysql].Groups["configuration_server"].Parameters["max_connections"].Value); err == nil {
+		serverMaxConn = maxConnections - AdminConnections
+	}
+	if serverMaxConn <= 0 {
+		return b, errors.New("max_connections not calculated, cannot size the HAProxy backend")
+	}
+	serverMaxConn = int(math.Ceil(float64(serverMaxConn*request.clusterNodes()) / float64(request.survivingNodes())))
+
+	params := haproxy.Parameters
+	b.WriteString("global\n")
+	fmt.Fprintf(&b, "  maxconn %s\n", params["maxconn"].Value)
+	b.WriteString("  external-check\n")
+	b.WriteString("  insecure-fork-wanted\n")
+	b.WriteString("\n")
+
+	b.WriteString("defaults\n")
+	b.WriteString("  default-server init-addr last,libc,none\n")
+	b.WriteString("  log global\n")
+	b.WriteString("  mode tcp\n")
+	b.WriteString("  retries 10\n")
+	fmt.Fprintf(&b, "  timeout client %ss\n", params["timeout_client"].Value)
+	fmt.Fprintf(&b, "  timeout connect %sms\n", params["timeout_connect"].Value)
+	fmt.Fprintf(&b, "  timeout server %ss\n", params["timeout_server"].Value)
+	fmt.Fprintf(&b, "  timeout check %sms\n", params["ha_connection_timeout"].Value)
+	b.WriteString("\n")
+
+	fmt.Fprintf(&b, "backend %s\n", backend)
+	b.WriteString("  mode tcp\n")
+	b.WriteString("  option srvtcpka\n")
+	b.WriteString("  balance roundrobin\n")
+	fmt.Fprintf(&b, "  fullconn %d\n", serverMaxConn)
+	fmt.Fprintf(&b, "  default-server check inter 10000 rise 1 fall 2 weight 1 maxconn %d\n", serverMaxConn)
+
+	return b, nil
+}
@@ -0,0 +1,89 @@
+package mysqloperatorcalculator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// Every stanza must be present, timeouts must carry units and the backend maxconn must follow max_connections.
+func TestHAProxyCfgOutput(t *testing.T) {
+	backends := map[string]string{DbTypePXC: "backend galera-nodes", DbTypeGroupReplication: "backend mysql-primary"}
+	for dbtype, backend := range backends {
+		t.Run(dbtype, func(t *testing.T) {
+			req := makeRequest(dbtype, 5, LoadTypeSomeWrites, 600)
+			err, msg, families := runCalculate(req)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var moc MysqlOperatorCalculator
+			b, err := moc.GetHAProxyCfgOutput(msg, req, families)
+			if err != nil {
+				t.Fatalf("GetHAProxyCfgOutput: %v", err)
+			}
+			out := b.String()
+
+			maxConnections, err := strconv.Atoi(families[FamilyTypeMysql].Groups["configuration_server"].Parameters["max_connections"].Value)
+			if err != nil {
+				t.Fatalf("max_connections: %v", err)
+			}
+			params := families[FamilyTypeProxy].Groups[GroupNameHAProxy].Parameters
+			for _, want := range []string{
+				"global\n",
+				"defaults\n",
+				backend + "\n",
+				fmt.Sprintf("  maxconn %d\n", 600*DefaultClusterNodes),
+				fmt.Sprintf("  timeout client %ss\n", params["timeout_client"].Value),
+				fmt.Sprintf("  timeout server %ss\n", params["timeout_server"].Value),
+				fmt.Sprintf("  timeout connect %sms\n", params["timeout_connect"].Value),
+				fmt.Sprintf("  timeout check %sms\n", params["ha_connection_timeout"].Value),
+				fmt.Sprintf(" maxconn %d\n", (maxConnections-AdminConnections)*DefaultClusterNodes/(DefaultClusterNodes-1)),
+			} {
+				if !strings.Contains(out, want) {
+					t.Errorf("output missing %q\n%s", want, out)
+				}
+			}
+			if maxConnections-AdminConnections != 600 {
+				t.Errorf("backend maxconn %d does not match the requested connections", maxConnections-AdminConnections)
+			}
+		})
+	}
+}
+
+// maxconn stays within the haproxyConfig bounds and never below what the surviving backend servers accept
+func TestHAProxyMaxConnBounds(t *testing.T) {
+	for connections, want := range map[int]string{100: "1000", 3000: "9000"} {
+		req := makeRequest(DbTypePXC, 8, LoadTypeMostlyReads, connections)
+		_, msg, families := runCalculate(req)
+		if v := families[FamilyTypeProxy].Groups[GroupNameHAProxy].Parameters["maxconn"].Value; v != want {
+			t.Errorf("%d connections: maxconn = %s, want %s", connections, v, want)
+		}
+
+		var moc MysqlOperatorCalculator
+		b, err := moc.GetHAProxyCfgOutput(msg, req, families)
+		if err != nil {
+			t.Fatalf("GetHAProxyCfgOutput: %v", err)
+		}
+		var global, server int
+		for _, line := range strings.Split(b.String(), "\n") {
+			if strings.HasPrefix(line, "  maxconn ") {
+				global, _ = strconv.Atoi(strings.TrimPrefix(line, "  maxconn "))
+			} else if i := strings.Index(line, " maxconn "); strings.HasPrefix(line, "  default-server check") && i > 0 {
+				server, _ = strconv.Atoi(line[i+len(" maxconn "):])
+			}
+		}
+		if server == 0 || global < server*(DefaultClusterNodes-1) {
+			t.Errorf("%d connections: global maxconn %d below the %d surviving servers at maxconn %d", connections, global, DefaultClusterNodes-1, server)
+		}
+	}
+}
+
+func TestHAProxyCfgOutput_EmptyFamilies(t *testing.T) {
+	var moc MysqlOperatorCalculator
+	req := makeRequest(DbTypePXC, 3, LoadTypeSomeWrites, 200)
+	if _, err := moc.GetHAProxyCfgOutput(ResponseMessage{}, req, map[string]Family{}); err == nil {
+		t.Error("expected error without a proxy family, got nil")
+	}
+}
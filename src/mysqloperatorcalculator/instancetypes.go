@@ -0,0 +1,86 @@
+package mysqloperatorcalculator
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+
+	"code.cloudfoundry.org/bytefmt"
+)
+
+//go:embed instancetypes.json
+var instanceTypesJSON []byte
+
+type InstanceType struct {
+	Name        string  `json:"name"`
+	Provider    string  `json:"provider"`
+	Cpu         int     `json:"cpu"`
+	Memory      string  `json:"memory"`
+	MemoryBytes float64 `json:"-"`
+	MaxPods     int     `json:"maxPods"`
+}
+
+type InstanceCatalog struct {
+	Version   string         `json:"version"`
+	Instances []InstanceType `json:"instances"`
+}
+
+var instanceCatalog = mustLoadInstanceCatalog()
+
+func mustLoadInstanceCatalog() InstanceCatalog {
+	var catalog InstanceCatalog
+	if err := json.Unmarshal(instanceTypesJSON, &catalog); err != nil {
+		panic(fmt.Sprintf("bundled instance catalog: %v", err))
+	}
+	for i := range catalog.Instances {
+		var dim Dimension
+		memory, err := dim.ConvertMemoryToBytes(catalog.Instances[i].Memory)
+		if err != nil {
+			panic(fmt.Sprintf("bundled instance catalog: %s: %v", catalog.Instances[i].Name, err))
+		}
+		catalog.Instances[i].MemoryBytes = memory
+	}
+	return catalog
+}
+
+// GetInstanceCatalog returns a copy of the bundled instance catalog
+func GetInstanceCatalog() InstanceCatalog {
+	catalog := instanceCatalog
+	catalog.Instances = append([]InstanceType(nil), instanceCatalog.Instances...)
+	return catalog
+}
+
+// LookupInstanceType returns the bundled instance type with the given name
+func LookupInstanceType(name string) (InstanceType, bool) {
+	for _, it := range instanceCatalog.Instances {
+		if it.Name == name {
+			return it, true
+		}
+	}
+	return InstanceType{}, false
+}
+
+// Deduct returns the deductions of the instance provider profile for this node shape
+func (it InstanceType) Deduct() ProviderDeductions {
+	profile, _ := GetProviderProfile(it.Provider)
+	return profile.Deduct(it.Cpu, it.MemoryBytes, it.MaxPods)
+}
+
+// Allocatable returns the CPU (millicores) and memory (bytes) left to pods once the kubelet
+// reservations, the eviction threshold and the provider DaemonSets are deducted
+func (it InstanceType) Allocatable() (int, float64) {
+	deductions := it.Deduct()
+	return deductions.AllocatableCpu, deductions.AllocatableMemory
+}
+
+// Dimension returns the open dimension matching what the instance type leaves to the pods
+func (it InstanceType) Dimension() Dimension {
+	cpu, memory := it.Allocatable()
+	return Dimension{
+		Id:          DimensionOpen,
+		Name:        it.Name,
+		Cpu:         cpu,
+		Memory:      bytefmt.ByteSize(uint64(memory)),
+		MemoryBytes: memory,
+	}
+}
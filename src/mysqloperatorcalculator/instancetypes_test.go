@@ -0,0 +1,55 @@
+package mysqloperatorcalculator
+
+import (
+	"testing"
+)
+
+// m6i.2xlarge: 8 vCPU, 32GiB, 58 pods on EKS
+func TestInstanceTypeAllocatable_EKS(t *testing.T) {
+	it, ok := LookupInstanceType("m6i.2xlarge")
+	if !ok {
+		t.Fatal("m6i.2xlarge missing from the bundled catalog")
+	}
+	cpu, memory := it.Allocatable()
+	if want := 8000 - 90 - 150; cpu != want {
+		t.Errorf("cpu = %d, want %d", cpu, want)
+	}
+	wantMemory := float64(32*1024-(255+11*58)-100-256) * 1048576
+	if memory != wantMemory {
+		t.Errorf("memory = %.0f, want %.0f", memory, wantMemory)
+	}
+
+	deductions := it.Deduct()
+	if deductions.Provider != ProviderEKS || deductions.MaxPods != 58 || len(deductions.Deductions) != 3 {
+		t.Errorf("unexpected deductions %+v", deductions)
+	}
+
+	dim := it.Dimension()
+	if dim.Id != DimensionOpen || dim.Name != "m6i.2xlarge" || dim.Cpu != cpu || dim.MemoryBytes != memory {
+		t.Errorf("unexpected dimension %+v", dim)
+	}
+}
+
+func TestBundledInstanceCatalog(t *testing.T) {
+	catalog := GetInstanceCatalog()
+	if catalog.Version == "" {
+		t.Error("bundled catalog has no version")
+	}
+	if len(catalog.Instances) == 0 {
+		t.Fatal("bundled catalog is empty")
+	}
+	seen := map[string]bool{}
+	for _, it := range catalog.Instances {
+		if seen[it.Name] {
+			t.Errorf("%s: duplicated instance type", it.Name)
+		}
+		seen[it.Name] = true
+		if _, ok := GetProviderProfile(it.Provider); !ok {
+			t.Errorf("%s: unknown provider %q", it.Name, it.Provider)
+		}
+		cpu, memory := it.Allocatable()
+		if cpu <= 0 || cpu >= it.Cpu || memory <= 0 || memory >= it.MemoryBytes {
+			t.Errorf("%s: allocatable cpu %d memory %.0f out of range", it.Name, cpu, memory)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package mysqloperatorcalculator
 
 import (
 	"strconv"
+	"strings"
 	"testing"
 )
 
@@ -25,6 +26,12 @@ func runCalculate(req ConfigurationRequest) (error, ResponseMessage, map[string]
 	return moc.GetCalculate()
 }
 
+// messageReport returns the report of the message by name, nil when the message has none
+func messageReport[T Report](msg ResponseMessage, name string) T {
+	report, _ := msg.Reports[name].(T)
+	return report
+}
+
 // redoLogCapacityBytes extracts innodb_redo_log_capacity from the returned families.
 func redoLogCapacityBytes(t *testing.T, families map[string]Family) int64 {
 	t.Helper()
@@ -173,6 +180,239 @@ func TestIntegration_InvalidDBType(t *testing.T) {
 	}
 }
 
+// ---------------------------------------------------------------------------
+// Async replication
+// ---------------------------------------------------------------------------
+
+func TestIntegration_Async_Medium_SomeWrites_OK(t *testing.T) {
+	req := makeRequest(DbTypeAsync, 3, LoadTypeSomeWrites, 100)
+	err, msg, families := runCalculate(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if msg.MType != OkI {
+		t.Errorf("expected OkI, got MType=%d", msg.MType)
+	}
+
+	mysql := families[FamilyTypeMysql]
+	for _, group := range []string{"configuration_galera", "configuration_groupReplication"} {
+		if _, ok := mysql.Groups[group]; ok {
+			t.Errorf("%s must not be present for async", group)
+		}
+	}
+	replica := mysql.Groups["configuration_replica"].Parameters
+	for name, want := range map[string]string{
+		"gtid_mode":                "ON",
+		"enforce_gtid_consistency": "ON",
+		"log_replica_updates":      "ON",
+		"relay_log_recovery":       "ON",
+		"binlog_format":            "ROW",
+	} {
+		if got := replica[name].Value; got != want {
+			t.Errorf("%s = %q, want %q", name, got, want)
+		}
+	}
+
+	// Without a GCS cache to reserve, the buffer pool must be larger than for group replication
+	_, _, grFamilies := runCalculate(makeRequest(DbTypeGroupReplication, 3, LoadTypeSomeWrites, 100))
+	if bp, grBp := bufferPoolBytes(t, families), bufferPoolBytes(t, grFamilies); bp <= grBp {
+		t.Errorf("async buffer pool %d should be larger than group replication %d", bp, grBp)
+	}
+}
+
+func TestIntegration_InvalidProxyType(t *testing.T) {
+	req := makeRequest(DbTypeGroupReplication, 2, LoadTypeMostlyReads, 50)
+	req.ProxyType = "nginx"
+	err, _, _ := runCalculate(req)
+	if err == nil {
+		t.Error("expected error for invalid ProxyType, got nil")
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Proxy type selection
+// ---------------------------------------------------------------------------
+
+func TestIntegration_ProxySQLFamily(t *testing.T) {
+	req := makeRequest(DbTypeGroupReplication, 3, LoadTypeSomeWrites, 100)
+	req.ProxyType = ProxyTypeProxySQL
+	err, _, families := runCalculate(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	proxy := families[FamilyTypeProxy]
+	if proxy.Name != "proxysql" {
+		t.Fatalf("proxy family name = %q, want proxysql", proxy.Name)
+	}
+	if _, ok := proxy.Groups[GroupNameHAProxy]; ok {
+		t.Error("haproxyConfig group must not be present for proxysql")
+	}
+	for _, group := range []string{GroupNameProxySQL, "resources", "readinessProbe", "livenessProbe"} {
+		if _, ok := proxy.Groups[group]; !ok {
+			t.Errorf("%s group missing from proxysql family", group)
+		}
+	}
+	if got := proxy.Groups[GroupNameProxySQL].Parameters["mysql_servers.max_connections"].Value; got != "100" {
+		t.Errorf("mysql_servers.max_connections = %s, want 100", got)
+	}
+}
+
+func TestIntegration_RouterFamily(t *testing.T) {
+	req := makeRequest(DbTypeGroupReplication, 3, LoadTypeSomeWrites, 100)
+	req.ProxyType = ProxyTypeRouter
+	err, msg, families := runCalculate(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	proxy := families[FamilyTypeProxy]
+	if proxy.Name != "router" {
+		t.Fatalf("proxy family name = %q, want router", proxy.Name)
+	}
+	if got := proxy.Groups[GroupNameRouter].Parameters["max_total_connections"].Value; got != strconv.Itoa(100*DefaultClusterNodes) {
+		t.Errorf("max_total_connections = %s, want %d", got, 100*DefaultClusterNodes)
+	}
+
+	var moc MysqlOperatorCalculator
+	b, err := moc.GetHumanOutput(msg, req, families)
+	if err != nil || !strings.Contains(b.String(), "[router]\n") {
+		t.Errorf("human output must carry the [router] section, err=%v", err)
+	}
+	if _, err := moc.GetJSONOutput(msg, req, families); err != nil {
+		t.Errorf("GetJSONOutput: %v", err)
+	}
+}
+
+func TestIntegration_RouterRejectsPXC(t *testing.T) {
+	req := makeRequest(DbTypePXC, 3, LoadTypeSomeWrites, 100)
+	req.ProxyType = ProxyTypeRouter
+	err, _, _ := runCalculate(req)
+	if err == nil {
+		t.Error("expected error for router with pxc, got nil")
+	}
+}
+
+func TestIntegration_InstanceType(t *testing.T) {
+	req := makeRequest(DbTypeGroupReplication, 0, LoadTypeSomeWrites, 200)
+	req.InstanceType = "n2-standard-8"
+	var conf Configuration
+	conf.Init()
+	var moc MysqlOperatorCalculator
+	req = moc.Init(req, conf)
+	err, msg, families := moc.GetCalculate()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if msg.MType != OkI {
+		t.Errorf("expected MType %d, got %d: %s", OkI, msg.MType, msg.MText)
+	}
+	if len(families) == 0 {
+		t.Error("expected families, got none")
+	}
+	it, _ := LookupInstanceType("n2-standard-8")
+	cpu, memory := it.Allocatable()
+	if req.Dimension.Id != DimensionOpen || req.Dimension.Cpu != cpu || req.Dimension.MemoryBytes != memory {
+		t.Errorf("dimension %+v does not match the n2-standard-8 allocatable resources", req.Dimension)
+	}
+}
+
+func TestIntegration_InstanceTypeErrors(t *testing.T) {
+	req := makeRequest(DbTypePXC, 0, LoadTypeSomeWrites, 100)
+	req.InstanceType = "m1.nonexistent"
+	if err, _, _ := runCalculate(req); err == nil {
+		t.Error("expected error for unknown instance type, got nil")
+	}
+
+	req.InstanceType = "m6i.xlarge"
+	req.ProviderCostPct = 0.1
+	if err, _, _ := runCalculate(req); err == nil {
+		t.Error("expected error for instancetype with providercostpct, got nil")
+	}
+}
+
+func TestIntegration_ProviderProfile(t *testing.T) {
+	req := makeRequest(DbTypePXC, DimensionOpen, LoadTypeSomeWrites, 200)
+	req.Dimension.Cpu = 8000
+	req.Dimension.MemoryBytes = 32 * testGB
+	req.Provider = ProviderGKE
+	var conf Configuration
+	conf.Init()
+	var moc MysqlOperatorCalculator
+	req = moc.Init(req, conf)
+	err, _, families := moc.GetCalculate()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(families) == 0 {
+		t.Error("expected families, got none")
+	}
+	d := req.ProviderDeductions
+	if d == nil {
+		t.Fatal("expected provider deductions in the request")
+	}
+	if d.Provider != ProviderGKE || d.NodeCpu != 8000 || d.AllocatableCpu >= 8000 || d.AllocatableMemory >= 32*testGB {
+		t.Errorf("unexpected deductions %+v", d)
+	}
+	if moc.IncomingRequest.Dimension.Cpu != d.AllocatableCpu {
+		t.Errorf("dimension cpu %d does not match the allocatable cpu %d", moc.IncomingRequest.Dimension.Cpu, d.AllocatableCpu)
+	}
+}
+
+func TestIntegration_ProviderProfileErrors(t *testing.T) {
+	cases := map[string]func(r *ConfigurationRequest){
+		"unknown provider":  func(r *ConfigurationRequest) { r.Provider = "nocloud" },
+		"predefined dim":    func(r *ConfigurationRequest) { r.Provider = ProviderEKS; r.Dimension = Dimension{Id: 3} },
+		"providercostpct":   func(r *ConfigurationRequest) { r.Provider = ProviderEKS; r.ProviderCostPct = 0.1 },
+		"instance mismatch": func(r *ConfigurationRequest) { r.Provider = ProviderGKE; r.InstanceType = "m6i.xlarge" },
+		"nothing left":      func(r *ConfigurationRequest) { r.Provider = ProviderOpenShift; r.Dimension.Cpu = 200 },
+	}
+	for name, mutate := range cases {
+		t.Run(name, func(t *testing.T) {
+			req := makeRequest(DbTypePXC, DimensionOpen, LoadTypeSomeWrites, 100)
+			req.Dimension.Cpu = 4000
+			req.Dimension.MemoryBytes = 16 * testGB
+			mutate(&req)
+			if err, _, _ := runCalculate(req); err == nil {
+				t.Error("expected error, got nil")
+			}
+		})
+	}
+}
+
+// The breakdown must match the returned parameters and carry the cache of the chosen dbtype only
+func TestIntegration_ResourceBreakdown(t *testing.T) {
+	for _, dbtype := range []string{DbTypePXC, DbTypeGroupReplication, DbTypeAsync} {
+		t.Run(dbtype, func(t *testing.T) {
+			req := makeRequest(dbtype, 4, LoadTypeSomeWrites, 200)
+			err, msg, families := runCalculate(req)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			bd := msg.Breakdown
+			if bd == nil {
+				t.Fatal("expected a resource breakdown in the message")
+			}
+			if bd.Memory.Mysql.BufferPool != bufferPoolBytes(t, families) {
+				t.Errorf("breakdown buffer pool %d, parameter %d", bd.Memory.Mysql.BufferPool, bufferPoolBytes(t, families))
+			}
+			if bd.Connections != 200 || bd.Cpu.Total == 0 || bd.Cpu.Mysql+bd.Cpu.Proxy+bd.Cpu.Pmm > bd.Cpu.Total {
+				t.Errorf("unexpected cpu split %+v for %d connections", bd.Cpu, bd.Connections)
+			}
+			if bd.Memory.Mysql.Allocated+bd.Memory.Proxy+bd.Memory.Pmm > bd.Memory.Total {
+				t.Errorf("memory shares exceed the total: %+v", bd.Memory)
+			}
+			if (bd.Memory.Mysql.Gcache > 0) != (dbtype == DbTypePXC) || (bd.Memory.Mysql.GcsCache > 0) != (dbtype == DbTypeGroupReplication) {
+				t.Errorf("unexpected caches for %s: %+v", dbtype, bd.Memory.Mysql)
+			}
+
+			var moc MysqlOperatorCalculator
+			b, err := moc.GetJSONOutput(msg, req, families)
+			if err != nil || !strings.Contains(b.String(), `"breakdown": {`) {
+				t.Errorf("JSON output must carry the breakdown, err=%v", err)
+			}
+		})
+	}
+}
+
 // ---------------------------------------------------------------------------
 // Auto-scale by connections (dimension ID 998)
 // ---------------------------------------------------------------------------
@@ -191,6 +431,14 @@ func TestIntegration_AutoScaleByConnections(t *testing.T) {
 	}
 }
 
+func TestIntegration_AutoScaleByConnections_NoDimensionFits(t *testing.T) {
+	req := makeRequest(DbTypePXC, ConnectionDimension, LoadTypeMostlyReads, 100000)
+	err, _, _ := runCalculate(req)
+	if err == nil || !strings.Contains(err.Error(), "no dimension fits the request") {
+		t.Errorf("expected the no dimension fits error, got %v", err)
+	}
+}
+
 // ---------------------------------------------------------------------------
 // Open dimension (ID 999)
 // ---------------------------------------------------------------------------
@@ -0,0 +1,106 @@
+package mysqloperatorcalculator
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// throughputISTMinutes is the minutes of writes GCache keeps for a node rejoining with IST when a throughput
+// target has no IST window
+const throughputISTMinutes = 30
+
+// IST is the recovery window a rejoining node must reach without SST
+type IST struct {
+	WindowMinutes int `json:"windowMinutes"`
+	// WriteBytesPerSec is the write volume GCache receives, the throughput target when not set
+	WriteBytesPerSec int64 `json:"writeBytesPerSec,omitempty"`
+}
+
+type ISTReport struct {
+	WindowMinutes    int   `json:"windowMinutes"`
+	WriteBytesPerSec int64 `json:"writeBytesPerSec"`
+	// Need is the GCache holding the window, Gcache the one set
+	Need   int64 `json:"need"`
+	Gcache int64 `json:"gcache"`
+	// AchievableMinutes is the window Gcache holds at the write rate
+	AchievableMinutes float64 `json:"achievableMinutes"`
+	Limited           bool    `json:"limited"`
+}
+
+func validateIST(request ConfigurationRequest) error {
+	ist := request.IST
+	switch {
+	case request.DBType != DbTypePXC:
+		return fmt.Errorf("ist only applies to DB Type %s", DbTypePXC)
+	case ist.WindowMinutes <= 0:
+		return errors.New("ist windowMinutes must be above 0")
+	case ist.WriteBytesPerSec < 0:
+		return errors.New("ist writeBytesPerSec cannot be negative")
+	case ist.WriteBytesPerSec == 0 && (request.Throughput == nil || request.Throughput.writeBytesPerSec() == 0):
+		return errors.New("ist needs writeBytesPerSec or a throughput with writes")
+	}
+	return nil
+}
+
+// istWindow returns the minutes GCache must hold and the write rate in bytes per second: the IST request,
+// throughputISTMinutes of a throughput target, false when the request gives no write rate
+func (r ConfigurationRequest) istWindow() (float64, float64, bool) {
+	var rate float64
+	if r.Throughput != nil {
+		rate = r.Throughput.writeBytesPerSec()
+	}
+	if r.IST != nil {
+		if r.IST.WriteBytesPerSec > 0 {
+			rate = float64(r.IST.WriteBytesPerSec)
+		}
+		return float64(r.IST.WindowMinutes), rate, true
+	}
+	return throughputISTMinutes, rate, rate > 0
+}
+
+// istGcache returns the GCache holding the IST window. With an IST request it is cut to the footprint the
+// memory left over and the buffer pool above its floor can pay, the throughput one is taken as it is
+func (c *Configurator) istGcache(footprintFactor float64) (int64, bool) {
+	minutes, rate, ok := c.request.istWindow()
+	if !ok {
+		return 0, false
+	}
+	gcache := int64(math.Ceil(rate * 60 * minutes))
+	if c.request.IST == nil {
+		return gcache, true
+	}
+	// the buffer pool floor EvaluateResources accepts
+	floor := int64(math.Ceil(c.reference.memory * minLimitByDBType(c.request.DBType)))
+	available := c.reference.memoryLeftover + c.reference.innoDBbpSize - floor
+	if maxGcache := int64(float64(available) / footprintFactor); gcache > maxGcache {
+		gcache = maxGcache
+		if gcache < 0 {
+			gcache = 0
+		}
+	}
+	return gcache, true
+}
+
+// getISTReport compares the GCache set, pinned or cut by the memory, with the window requested
+func (c *Configurator) getISTReport() *ISTReport {
+	minutes, rate, _ := c.request.istWindow()
+	report := &ISTReport{
+		WindowMinutes:    int(minutes),
+		WriteBytesPerSec: int64(math.Ceil(rate)),
+		Need:             int64(math.Ceil(rate * 60 * minutes)),
+		Gcache:           c.reference.gcache,
+	}
+	report.AchievableMinutes = math.Floor(float64(report.Gcache)/(rate*60)*10) / 10
+	report.Limited = report.Gcache < report.Need
+	return report
+}
+
+// text warns when the window was cut
+func (r *ISTReport) text() string {
+	if r.Limited {
+		return fmt.Sprintf("\n!!!! GCache of %d bytes holds %.1f minutes of writes, the IST window of %d minutes needs %d bytes: a node down longer rejoins with SST !!!!\n",
+			r.Gcache, r.AchievableMinutes, r.WindowMinutes, r.Need)
+	}
+	return fmt.Sprintf("\nIST window = %d minutes, GCache = %d bytes\n", r.WindowMinutes, r.Gcache)
+}
@@ -0,0 +1,85 @@
+package mysqloperatorcalculator
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestIST_Calculate(t *testing.T) {
+	req := makeRequest(DbTypePXC, 4, LoadTypeSomeWrites, 200)
+	req.IST = &IST{WindowMinutes: 30, WriteBytesPerSec: 1048576}
+	err, msg, families := runCalculate(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	report := messageReport[*ISTReport](msg, ReportIST)
+	if report == nil || report.Limited || report.Gcache != 30*60*1048576 || report.AchievableMinutes != 30 {
+		t.Errorf("a 30 minutes window must fit: %+v", report)
+	}
+	options := splitProviderOptions(families[FamilyTypeMysql].Groups["configuration_galera"].Parameters["wsrep-provider-options"].Value)
+	if options["gcache.size"] != strconv.Itoa(30*60*1048576) {
+		t.Errorf("gcache.size = %s, want the window of writes", options["gcache.size"])
+	}
+	footprint := int64(math.Ceil(float64(report.Gcache) * GcacheFootPrintFactorLightWrite))
+	if msg.Breakdown.Memory.Mysql.Gcache != report.Gcache || msg.Breakdown.Memory.Mysql.GcacheFootprint != footprint {
+		t.Errorf("the GCache footprint %d must be in the memory accounting: %+v", footprint, msg.Breakdown.Memory.Mysql)
+	}
+
+	// A window the memory cannot hold is cut, the buffer pool keeps its floor
+	req.IST.WindowMinutes = 600
+	err, msg, _ = runCalculate(req)
+	if err != nil {
+		t.Fatalf("a window larger than the memory must be cut, not rejected: %v", err)
+	}
+	report = messageReport[*ISTReport](msg, ReportIST)
+	if !report.Limited || report.Need != 600*60*1048576 || report.Gcache >= report.Need {
+		t.Errorf("the window must be cut below the GCache it needs: %+v", report)
+	}
+	if want := math.Floor(float64(report.Gcache)/(1048576*60)*10) / 10; report.AchievableMinutes != want {
+		t.Errorf("achievable window = %v minutes, want the %v the cut GCache holds", report.AchievableMinutes, want)
+	}
+	if !strings.Contains(msg.MText, "rejoins with SST") {
+		t.Errorf("message text must warn about the window: %s", msg.MText)
+	}
+	// The cut GCache fits next to the buffer pool and the connection buffers, on the smallest pods too
+	for _, dimension := range []int{1, 2, 4} {
+		limited := makeRequest(DbTypePXC, dimension, LoadTypeSomeWrites, 100)
+		limited.IST = &IST{WindowMinutes: 600, WriteBytesPerSec: 10485760}
+		_, msg, _ = runCalculate(limited)
+		if report := messageReport[*ISTReport](msg, ReportIST); msg.Breakdown == nil || !report.Limited {
+			t.Fatalf("dimension %d: the window must be cut: %+v", dimension, report)
+		}
+		mysql := msg.Breakdown.Memory.Mysql
+		if used := mysql.BufferPool + mysql.GcacheFootprint + mysql.ConnectionBuffers; used > mysql.Allocated {
+			t.Errorf("dimension %d: buffer pool %d, GCache footprint %d and connection buffers %d take %d, above the %d of mysql",
+				dimension, mysql.BufferPool, mysql.GcacheFootprint, mysql.ConnectionBuffers, used, mysql.Allocated)
+		}
+	}
+
+	// The throughput write rate is used when the IST request has none
+	req = makeRequest(DbTypePXC, 4, 0, 0)
+	req.Throughput = &Throughput{ReadsPerSec: 1000, WritesPerSec: 500, AvgWriteBytes: 1024}
+	req.IST = &IST{WindowMinutes: 10}
+	_, msg, _ = runCalculate(req)
+	if report = messageReport[*ISTReport](msg, ReportIST); report == nil || report.Gcache != 10*60*500*1024 || report.WriteBytesPerSec != 500*1024 {
+		t.Errorf("IST from the throughput rate = %+v", report)
+	}
+
+	for name, tc := range map[string]struct {
+		dbtype string
+		ist    IST
+	}{
+		"group replication": {DbTypeGroupReplication, IST{WindowMinutes: 30, WriteBytesPerSec: 1048576}},
+		"no window":         {DbTypePXC, IST{WriteBytesPerSec: 1048576}},
+		"no write rate":     {DbTypePXC, IST{WindowMinutes: 30}},
+		"negative rate":     {DbTypePXC, IST{WindowMinutes: 30, WriteBytesPerSec: -1}},
+	} {
+		req := makeRequest(tc.dbtype, 4, LoadTypeSomeWrites, 100)
+		req.IST = &tc.ist
+		if err, _, _ := runCalculate(req); err == nil {
+			t.Errorf("%s: expected an ist error", name)
+		}
+	}
+}
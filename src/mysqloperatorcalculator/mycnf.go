@@ -0,0 +1,128 @@
+package mysqloperatorcalculator
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// GetMyCnfOutput renders the mysql family as an option file that mysqld can load. Only the [mysqld] section
+// is written, the diagnostic message is kept as a comment header
+func (moc *MysqlOperatorCalculator) GetMyCnfOutput(message ResponseMessage, request ConfigurationRequest, families map[string]Family) (bytes.Buffer, error) {
+	var b bytes.Buffer
+
+	mysql, ok := families[FamilyTypeMysql]
+	if !ok {
+		return b, errors.New("no mysql configuration to render, see message for details")
+	}
+
+	fmt.Fprintf(&b, "# my.cnf generated by mysqloperatorcalculator %s\n", VERSION)
+	fmt.Fprintf(&b, "# dbtype: %s mysqlversion: %d.%d.%d\n", request.DBType, request.Mysqlversion.Major, request.Mysqlversion.Minor, request.Mysqlversion.Patch)
+	fmt.Fprintf(&b, "# message: %d %s\n", message.MType, message.MName)
+	for _, line := range strings.Split(strings.TrimSpace(message.MText), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			b.WriteString("#\n")
+			continue
+		}
+		fmt.Fprintf(&b, "#   %s\n", line)
+	}
+	b.WriteString("\n")
+
+	mysqld := mysql.ParseGroupsMysqld("")
+	b.Write(mysqld.Bytes())
+	return b, nil
+}
+
+// ParseMyCnf reads an option file and returns its values by section and option name.
+// It follows the mysqld option file rules: # and ; start a comment, values may be quoted,
+// options without a value are returned with an empty value, !include directives are skipped
+func ParseMyCnf(r io.Reader) (map[string]map[string]string, error) {
+	sections := make(map[string]map[string]string)
+	current := ""
+	lineNumber := 0
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") || strings.HasPrefix(line, "!") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			end := strings.Index(line, "]")
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: unterminated section header %q", lineNumber, line)
+			}
+			current = strings.TrimSpace(line[1:end])
+			if _, ok := sections[current]; !ok {
+				sections[current] = make(map[string]string)
+			}
+			continue
+		}
+		if current == "" {
+			return nil, fmt.Errorf("line %d: option %q outside of a section", lineNumber, line)
+		}
+
+		name, value, _ := strings.Cut(line, "=")
+		value, err := parseMyCnfValue(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", lineNumber, err)
+		}
+		sections[current][strings.TrimSpace(name)] = value
+	}
+
+	return sections, scanner.Err()
+}
+
+// parseMyCnfValue strips quotes or a trailing comment from an option value
+func parseMyCnfValue(value string) (string, error) {
+	if value == "" {
+		return value, nil
+	}
+
+	quote := value[0]
+	if quote == '"' || quote == '\'' {
+		var b strings.Builder
+		for i := 1; i < len(value); i++ {
+			switch {
+			case value[i] == '\\' && i+1 < len(value):
+				i++
+				b.WriteByte(myCnfEscapes(value[i]))
+			case value[i] == quote:
+				return b.String(), nil
+			default:
+				b.WriteByte(value[i])
+			}
+		}
+		return "", fmt.Errorf("unterminated quoted value %s", value)
+	}
+
+	if i := strings.Index(value, "#"); i >= 0 {
+		value = strings.TrimSpace(value[:i])
+	}
+	return value, nil
+}
+
+// myCnfEscapes maps the escape sequences accepted by the option file parser
+func myCnfEscapes(c byte) byte {
+	switch c {
+	case 'b':
+		return '\b'
+	case 't':
+		return '\t'
+	case 'n':
+		return '\n'
+	case 'r':
+		return '\r'
+	case 's':
+		return ' '
+	default:
+		return c
+	}
+}
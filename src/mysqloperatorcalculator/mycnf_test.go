@@ -0,0 +1,130 @@
+package mysqloperatorcalculator
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// The option file must parse back to exactly the parameters of the mysql family, in a single [mysqld] section.
+func TestMyCnfOutput_RoundTrip(t *testing.T) {
+	for _, dbtype := range []string{DbTypePXC, DbTypeGroupReplication, DbTypeAsync} {
+		t.Run(dbtype, func(t *testing.T) {
+			req := makeRequest(dbtype, 3, LoadTypeSomeWrites, 200)
+			err, msg, families := runCalculate(req)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var moc MysqlOperatorCalculator
+			b, err := moc.GetMyCnfOutput(msg, req, families)
+			if err != nil {
+				t.Fatalf("GetMyCnfOutput: %v", err)
+			}
+
+			sections, err := ParseMyCnf(bytes.NewReader(b.Bytes()))
+			if err != nil {
+				t.Fatalf("ParseMyCnf: %v\n%s", err, b.String())
+			}
+			if len(sections) != 1 {
+				t.Fatalf("expected only the [mysqld] section, got %d sections", len(sections))
+			}
+			mysqld, ok := sections["mysqld"]
+			if !ok {
+				t.Fatal("[mysqld] section missing")
+			}
+
+			expected := 0
+			for key, group := range families[FamilyTypeMysql].Groups {
+				if key == "readinessProbe" || key == "livenessProbe" || key == "resources" {
+					continue
+				}
+				for name, param := range group.Parameters {
+					expected++
+					got, ok := mysqld[name]
+					if !ok {
+						t.Errorf("%s missing from option file", name)
+						continue
+					}
+					if got != param.Value {
+						t.Errorf("%s = %q after round trip, want %q", name, got, param.Value)
+					}
+				}
+			}
+			if len(mysqld) != expected {
+				t.Errorf("option file holds %d options, family holds %d", len(mysqld), expected)
+			}
+		})
+	}
+}
+
+func TestMyCnfOutput_MessageIsCommented(t *testing.T) {
+	req := makeRequest(DbTypePXC, 2, LoadTypeMostlyReads, 50)
+	_, msg, families := runCalculate(req)
+
+	var moc MysqlOperatorCalculator
+	b, err := moc.GetMyCnfOutput(msg, req, families)
+	if err != nil {
+		t.Fatalf("GetMyCnfOutput: %v", err)
+	}
+	for _, line := range strings.Split(b.String(), "\n") {
+		if strings.HasPrefix(line, "[") && line != "[mysqld]" {
+			t.Errorf("unexpected section %q in option file", line)
+		}
+	}
+	if !strings.Contains(b.String(), `wsrep-provider-options = "`) {
+		t.Error("wsrep-provider-options must be quoted")
+	}
+}
+
+func TestParseMyCnf(t *testing.T) {
+	input := `
+# comment
+!includedir /etc/my.cnf.d
+[client]
+port = 3306
+[mysqld]
+skip-name-resolve
+innodb_buffer_pool_size = 1G   # trailing comment
+wsrep_provider_options = "gcache.size=1G; pc.recovery=true"
+sql_mode='STRICT_TRANS_TABLES'
+escaped = "a\"b\sc"
+`
+	sections, err := ParseMyCnf(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cases := []struct {
+		section, name, want string
+	}{
+		{"client", "port", "3306"},
+		{"mysqld", "skip-name-resolve", ""},
+		{"mysqld", "innodb_buffer_pool_size", "1G"},
+		{"mysqld", "wsrep_provider_options", "gcache.size=1G; pc.recovery=true"},
+		{"mysqld", "sql_mode", "STRICT_TRANS_TABLES"},
+		{"mysqld", "escaped", `a"b c`},
+	}
+	for _, tc := range cases {
+		got, ok := sections[tc.section][tc.name]
+		if !ok {
+			t.Errorf("[%s] %s missing", tc.section, tc.name)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("[%s] %s = %q, want %q", tc.section, tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestParseMyCnf_Invalid(t *testing.T) {
+	for _, input := range []string{
+		"max_connections = 10\n",          // option before any section
+		"[mysqld\nmax_connections = 10\n", // unterminated header
+		"[mysqld]\nsql_mode = \"STRICT\n", // unterminated quote
+	} {
+		if _, err := ParseMyCnf(strings.NewReader(input)); err == nil {
+			t.Errorf("expected error for %q, got nil", input)
+		}
+	}
+}
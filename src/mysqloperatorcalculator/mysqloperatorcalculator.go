@@ -5,8 +5,10 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
 	"os"
 	"strconv"
+	"strings"
 
 	"code.cloudfoundry.org/bytefmt"
 	log "github.com/sirupsen/logrus"
@@ -21,9 +23,23 @@ type MysqlOperatorCalculator struct {
 func (moc *MysqlOperatorCalculator) Init(inR ConfigurationRequest, conf Configuration) ConfigurationRequest {
 	moc.IncomingRequest = inR
 	moc.Conf = conf
-	if moc.IncomingRequest.ProviderCostPct > 0 {
+	switch {
+	case moc.IncomingRequest.InstanceType != "":
+		moc.adjustResourcesByInstanceType()
+	case moc.IncomingRequest.Provider != "":
+		moc.adjustResourcesByProviderProfile()
+	case moc.IncomingRequest.ProviderCostPct > 0:
 		moc.adjustResourcesByProvider()
 	}
+	if moc.IncomingRequest.GlobalStatus != "" {
+		moc.adjustByGlobalStatus()
+	}
+	if t := moc.IncomingRequest.Throughput; t != nil && validateThroughput(*t) == nil {
+		t.apply(&moc.IncomingRequest)
+	}
+	if ratio := moc.IncomingRequest.WriteRatio; ratio != nil && moc.IncomingRequest.LoadType.Id == 0 && *ratio >= 0 && *ratio <= 1 {
+		moc.IncomingRequest.LoadType.Id = nearestLoadType(*ratio)
+	}
 	moc.GetConfForConfRequest()
 	return moc.IncomingRequest
 }
@@ -39,15 +55,8 @@ func (moc *MysqlOperatorCalculator) GetCalculate() (error, ResponseMessage, map[
 	var ConfRequest = moc.IncomingRequest
 	calculateByConnection := false
 
-	// Check incoming request; return an error immediately if malformed
-	if ConfRequest.Dimension.Id == 0 || ConfRequest.LoadType.Id == 0 {
-		return fmt.Errorf("Possible Malformed request, Dimension ID: %d; LoadType ID: %d", ConfRequest.Dimension.Id, ConfRequest.LoadType.Id), responseMsg, families
-	} else if ConfRequest.Dimension.Id == DimensionOpen && (ConfRequest.Dimension.Cpu == 0 || ConfRequest.Dimension.MemoryBytes == 0) {
-		return fmt.Errorf("Open dimension request missing CPU OR Memory value CPU: %d, Memory %s", ConfRequest.Dimension.Cpu, ConfRequest.Dimension.Memory), responseMsg, families
-	}
-
-	if ConfRequest.DBType != DbTypePXC && ConfRequest.DBType != DbTypeGroupReplication {
-		return fmt.Errorf("DB Type is not correct. Supported Types are: %s, %s", DbTypePXC, DbTypeGroupReplication), responseMsg, families
+	if err := moc.validateRequest(); err != nil {
+		return err, responseMsg, families
 	}
 
 	// If calculating by connection (id = 998) and valid number for connection
@@ -65,7 +74,10 @@ func (moc *MysqlOperatorCalculator) GetCalculate() (error, ResponseMessage, map[
 	// Calculate the resources by the number of given connections
 	if calculateByConnection {
 		for message.MType == OverutilizingI {
-			dimension, _ := moc.Conf.ScaleDimension(moc.IncomingRequest.Dimension)
+			dimension, err := moc.Conf.ScaleDimension(moc.IncomingRequest.Dimension)
+			if err != nil {
+				return fmt.Errorf("no dimension fits the request: %v", err), message, Families
+			}
 			moc.IncomingRequest.Dimension = dimension
 			calcErr, message, Families = moc.getCalculateInt()
 		}
@@ -95,7 +107,8 @@ func (moc *MysqlOperatorCalculator) GetCalculate() (error, ResponseMessage, map[
 		calcErr, message, Families = moc.getCalculateInt()
 	}
 
-	if message.MType == OverutilizingI {
+	// The connections of a throughput target are what the target needs, they are not lowered to fit
+	if message.MType == OverutilizingI && moc.IncomingRequest.Throughput == nil {
 		originalConnections := moc.IncomingRequest.Connections
 		for message.MType == OverutilizingI && moc.IncomingRequest.Connections > MinConnectionNumber {
 			moc.IncomingRequest.Connections -= 10
@@ -106,12 +119,154 @@ func (moc *MysqlOperatorCalculator) GetCalculate() (error, ResponseMessage, map[
 		message.MType = ConnectionRecalculated
 	}
 
+	// The memory the hot set does not need is given back as a smaller dimension
+	workingSet, _ := message.Reports[ReportWorkingSet].(*WorkingSetReport)
+	if s := moc.IncomingRequest.Storage; s != nil && s.ReleaseMemory && workingSet != nil && workingSet.Saved > 0 {
+		calcErr, message, Families = moc.releaseWorkingSetMemory(calcErr, message, Families)
+	}
+
 	return calcErr, message, Families
 }
 
+// validateRequest checks the incoming request before any calculation; it returns the first problem found
+func (moc *MysqlOperatorCalculator) validateRequest() error {
+	ConfRequest := moc.IncomingRequest
+
+	// Init keeps the snapshot only when it could not derive a profile from it
+	if ConfRequest.GlobalStatus != "" {
+		if _, err := profileFromGlobalStatus(ConfRequest.GlobalStatus); err != nil {
+			return err
+		}
+	}
+	if ConfRequest.Provider != "" {
+		if _, ok := GetProviderProfile(ConfRequest.Provider); !ok {
+			return fmt.Errorf("Provider %s is not correct. Supported providers are: %s", ConfRequest.Provider, strings.Join(providerNames(), ", "))
+		} else if ConfRequest.ProviderCostPct > 0 {
+			return errors.New("providercostpct cannot be combined with provider, the provider profile already accounts for the platform reservations")
+		} else if ConfRequest.InstanceType == "" && ConfRequest.Dimension.Id != DimensionOpen {
+			return fmt.Errorf("Provider %s requires an open dimension (%d) or an instancetype", ConfRequest.Provider, DimensionOpen)
+		}
+	}
+	if ConfRequest.InstanceType != "" {
+		if it, ok := LookupInstanceType(ConfRequest.InstanceType); !ok {
+			return fmt.Errorf("Instance type %s is not in the instance catalog version %s", ConfRequest.InstanceType, instanceCatalog.Version)
+		} else if ConfRequest.ProviderCostPct > 0 {
+			return errors.New("providercostpct cannot be combined with instancetype, the instance type already accounts for the provider reservations")
+		} else if ConfRequest.Provider != "" && ConfRequest.Provider != it.Provider {
+			return fmt.Errorf("Instance type %s belongs to provider %s, not %s", it.Name, it.Provider, ConfRequest.Provider)
+		}
+	}
+
+	if ratio := ConfRequest.WriteRatio; ratio != nil {
+		if ConfRequest.LoadType.Id == LoadTypeAnalytics {
+			return errors.New("writeratio only applies to the OLTP load types, not to Analytics")
+		} else if *ratio < 0 || *ratio > 1 {
+			return fmt.Errorf("writeratio must be between 0.0 and 1.0, got %g", *ratio)
+		} else if ConfRequest.LoadType.Id != 0 && ConfRequest.LoadType.Id != nearestLoadType(*ratio) {
+			return fmt.Errorf("LoadType ID %d does not match writeratio %g (closest load type %d), set only the writeratio", ConfRequest.LoadType.Id, *ratio, nearestLoadType(*ratio))
+		}
+	}
+
+	// Check incoming request; return an error immediately if malformed
+	if ConfRequest.Dimension.Id == 0 || ConfRequest.LoadType.Id == 0 {
+		return fmt.Errorf("Possible Malformed request, Dimension ID: %d; LoadType ID: %d", ConfRequest.Dimension.Id, ConfRequest.LoadType.Id)
+	} else if ConfRequest.Dimension.Id == DimensionOpen && (ConfRequest.Dimension.Cpu <= 0 || ConfRequest.Dimension.MemoryBytes <= 0) {
+		return fmt.Errorf("Open dimension request missing CPU OR Memory value CPU: %d, Memory %s", ConfRequest.Dimension.Cpu, ConfRequest.Dimension.Memory)
+	}
+
+	if ConfRequest.DBType != DbTypePXC && ConfRequest.DBType != DbTypeGroupReplication && ConfRequest.DBType != DbTypeAsync {
+		return fmt.Errorf("DB Type is not correct. Supported Types are: %s, %s, %s", DbTypePXC, DbTypeGroupReplication, DbTypeAsync)
+	}
+
+	if ConfRequest.ProxyType != "" && ConfRequest.ProxyType != ProxyTypeHAProxy && ConfRequest.ProxyType != ProxyTypeProxySQL && ConfRequest.ProxyType != ProxyTypeRouter {
+		return fmt.Errorf("Proxy Type is not correct. Supported Types are: %s, %s, %s", ProxyTypeHAProxy, ProxyTypeProxySQL, ProxyTypeRouter)
+	} else if ConfRequest.ProxyType == ProxyTypeRouter && ConfRequest.DBType != DbTypeGroupReplication {
+		return fmt.Errorf("Proxy Type %s is only supported with DB Type %s", ProxyTypeRouter, DbTypeGroupReplication)
+	}
+
+	if err := validateTopology(ConfRequest); err != nil {
+		return err
+	}
+	if err := validateConsistency(ConfRequest); err != nil {
+		return err
+	}
+
+	if ConfRequest.Throughput != nil {
+		if err := validateThroughput(*ConfRequest.Throughput); err != nil {
+			return err
+		}
+	}
+	if ConfRequest.Storage != nil {
+		if err := validateStorage(*ConfRequest.Storage); err != nil {
+			return err
+		}
+	}
+	if ConfRequest.Network != nil {
+		if err := validateNetwork(*ConfRequest.Network, ConfRequest); err != nil {
+			return err
+		}
+	}
+	if ConfRequest.IST != nil {
+		if err := validateIST(ConfRequest); err != nil {
+			return err
+		}
+	}
+
+	if len(ConfRequest.Overrides) > 0 {
+		if err := validateOverrides(ConfRequest, requestFamilies(ConfRequest)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// requestFamilies returns the families of the request, with the parameters only some requests set
+func requestFamilies(request ConfigurationRequest) map[string]Family {
+	var family Family
+	families := family.Init(request.DBType, request.ProxyType)
+	if request.LoadType.Id == LoadTypeAnalytics {
+		addParameters(families, analyticsParameters())
+	}
+	if request.Throughput != nil {
+		addParameters(families, throughputParameters())
+	}
+	if request.topology() && request.DBType == DbTypeGroupReplication {
+		addParameters(families, topologyParameters())
+	}
+	if request.Storage != nil && request.Storage.Iops > 0 {
+		addParameters(families, storageParameters())
+	}
+	if request.groupProfile() {
+		addParameters(families, consistencyParameters())
+	}
+	if request.Network != nil && request.DBType == DbTypeGroupReplication {
+		addParameters(families, networkParameters())
+	}
+	return families
+}
+
+// reporters are the reports added to the message, in this order, when the request has their input
+var reporters = []struct {
+	name      string
+	requested func(ConfigurationRequest) bool
+	report    func(*Configurator) Report
+}{
+	{ReportThroughput, func(r ConfigurationRequest) bool { return r.Throughput != nil },
+		func(c *Configurator) Report { return c.getThroughputReport(true) }},
+	{ReportStorage, func(r ConfigurationRequest) bool { return r.Storage != nil },
+		func(c *Configurator) Report { return c.getStorageReport() }},
+	{ReportWorkingSet, func(r ConfigurationRequest) bool { return r.Storage != nil && r.Storage.HotSetPct > 0 },
+		func(c *Configurator) Report { return c.getWorkingSetReport() }},
+	{ReportNetwork, func(r ConfigurationRequest) bool { return r.Network != nil },
+		func(c *Configurator) Report { return c.getNetworkReport() }},
+	{ReportIST, func(r ConfigurationRequest) bool { return r.IST != nil },
+		func(c *Configurator) Report { return c.getISTReport() }},
+	{ReportConsistency, ConfigurationRequest.groupProfile,
+		func(c *Configurator) Report { return c.getConsistencyReport() }},
+}
+
 func (moc *MysqlOperatorCalculator) getCalculateInt() (error, ResponseMessage, map[string]Family) {
 	var responseMsg ResponseMessage
-	var family Family
 	var conf Configuration
 
 	ConfRequest := moc.IncomingRequest
@@ -123,17 +278,29 @@ func (moc *MysqlOperatorCalculator) getCalculateInt() (error, ResponseMessage, m
 		moc.GetConfForConfRequest()
 	}
 
-	families := family.Init(ConfRequest.DBType)
+	families := requestFamilies(ConfRequest)
 	responseMsg, connectionsOverload := moc.configurator.Init(ConfRequest, families, conf, responseMsg)
 
 	if connectionsOverload {
 		responseMsg.MName = "Resources Overload"
 		responseMsg.MText = "Too many connections for the chosen dimension. Resource Overload, decrease number of connections OR choose higher CPUs value"
+		if ConfRequest.Throughput != nil {
+			report := moc.configurator.getThroughputReport(false)
+			if report.Binding == ConstraintCpu {
+				responseMsg.MText = "Not enough CPU for the throughput target. Resource Overload, choose higher CPUs value"
+			}
+			responseMsg.addReport(ReportThroughput, report)
+		}
 		families = make(map[string]Family)
 	} else {
 		overUtilizing := false
 		moc.configurator.ProcessRequest()
 		responseMsg, overUtilizing = moc.configurator.EvaluateResources(responseMsg)
+		for _, r := range reporters {
+			if r.requested(ConfRequest) {
+				responseMsg.addReport(r.name, r.report(&moc.configurator))
+			}
+		}
 
 		if overUtilizing {
 			families = make(map[string]Family)
@@ -277,6 +444,42 @@ func (moc *MysqlOperatorCalculator) GetConfForConfRequest() {
 	}
 }
 
+// adjustByGlobalStatus fills the load type and connections the request leaves unset from the snapshot, the profile gives the tmp_table_size.
+// A snapshot that cannot be read is left in the request, GetCalculate reports it
+func (moc *MysqlOperatorCalculator) adjustByGlobalStatus() {
+	profile, err := profileFromGlobalStatus(moc.IncomingRequest.GlobalStatus)
+	if err != nil {
+		return
+	}
+	profile.apply(&moc.IncomingRequest)
+	moc.IncomingRequest.StatusProfile = &profile
+	moc.IncomingRequest.GlobalStatus = ""
+}
+
+// adjustResourcesByInstanceType replaces the requested dimension with the allocatable resources of the instance type.
+// Unknown instance types leave the request untouched, GetCalculate reports them
+func (moc *MysqlOperatorCalculator) adjustResourcesByInstanceType() {
+	if it, ok := LookupInstanceType(moc.IncomingRequest.InstanceType); ok {
+		deductions := it.Deduct()
+		moc.IncomingRequest.Dimension = it.Dimension()
+		moc.IncomingRequest.ProviderDeductions = &deductions
+	}
+}
+
+// adjustResourcesByProviderProfile treats the open dimension as a whole node and deducts what the provider keeps on it.
+// Unknown providers and non open dimensions leave the request untouched, GetCalculate reports them
+func (moc *MysqlOperatorCalculator) adjustResourcesByProviderProfile() {
+	profile, ok := GetProviderProfile(moc.IncomingRequest.Provider)
+	if !ok || moc.IncomingRequest.Dimension.Id != DimensionOpen {
+		return
+	}
+	deductions := profile.Deduct(moc.IncomingRequest.Dimension.Cpu, moc.IncomingRequest.Dimension.MemoryBytes, 0)
+	moc.IncomingRequest.Dimension.Cpu = deductions.AllocatableCpu
+	moc.IncomingRequest.Dimension.MemoryBytes = deductions.AllocatableMemory
+	moc.IncomingRequest.Dimension.Memory = bytefmt.ByteSize(uint64(math.Max(deductions.AllocatableMemory, 0)))
+	moc.IncomingRequest.ProviderDeductions = &deductions
+}
+
 func (moc *MysqlOperatorCalculator) adjustResourcesByProvider() {
 	moc.IncomingRequest.Dimension.Cpu = int(float64(moc.IncomingRequest.Dimension.Cpu) * (1.0 - moc.IncomingRequest.ProviderCostPct))
 	moc.IncomingRequest.Dimension.MemoryBytes = float64(moc.IncomingRequest.Dimension.MemoryBytes) * (1.0 - moc.IncomingRequest.ProviderCostPct)
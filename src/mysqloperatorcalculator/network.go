@@ -0,0 +1,196 @@
+package mysqloperatorcalculator
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+const (
+	// networkWanRtt is the round trip, in milliseconds, from which the members are treated as in different datacenters
+	networkWanRtt = 5.0
+	// networkSuspectRtts is the round trips a member may miss before it is suspected
+	networkSuspectRtts = 100
+	// networkPeerTimeoutRtts is the round trips a Galera peer connection may stay silent
+	networkPeerTimeoutRtts = 30
+	// networkWindowPerRttMs is the Galera send window, in messages, for every millisecond of round trip
+	networkWindowPerRttMs = 4
+	// networkWanMaxMessage is the largest group replication message on a WAN, larger transactions are fragmented
+	networkWanMaxMessage = 2097152
+)
+
+// Network is the link between the members
+type Network struct {
+	// RttMs is the round trip between the farthest members in milliseconds
+	RttMs float64 `json:"rttMs"`
+	// Segments is the number of members in each datacenter, Segment the one this configuration is for
+	Segments []int `json:"segments,omitempty"`
+	Segment  int   `json:"segment,omitempty"`
+}
+
+type NetworkReport struct {
+	RttMs float64 `json:"rttMs"`
+	Wan   bool    `json:"wan"`
+	// CommitPenaltyMs is the latency each commit adds waiting for the other members
+	CommitPenaltyMs float64 `json:"commitPenaltyMs"`
+	// MaxCommitsPerConnection is the commits per second a connection committing one transaction after the other reaches
+	MaxCommitsPerConnection int      `json:"maxCommitsPerConnection,omitempty"`
+	Notes                   []string `json:"notes"`
+}
+
+func networkParameters() map[string]map[string]Parameter {
+	return map[string]map[string]Parameter{
+		"configuration_groupReplication": {
+			"loose_group_replication_unreachable_majority_timeout": {"loose_group_replication_unreachable_majority_timeout", "configuration", "groupReplication", "3600", "0", 0, 3600, MySQLVersions{V8_0_46, V11_1_1}},
+		},
+	}
+}
+
+func validateNetwork(n Network, request ConfigurationRequest) error {
+	if n.RttMs <= 0 {
+		return errors.New("network rttMs must be above 0")
+	}
+	if len(n.Segments) == 0 {
+		if n.Segment != 0 {
+			return errors.New("network segment needs the segments layout")
+		}
+		return nil
+	}
+	members := 0
+	for _, m := range n.Segments {
+		if m < 1 {
+			return errors.New("network segments must have at least one member each")
+		}
+		members += m
+	}
+	if n.Segment < 0 || n.Segment >= len(n.Segments) {
+		return fmt.Errorf("network segment must be between 0 and %d", len(n.Segments)-1)
+	}
+	if request.Nodes != 0 && members != request.Nodes {
+		return fmt.Errorf("network segments hold %d members, nodes is %d", members, request.Nodes)
+	}
+	return nil
+}
+
+// members returns the members of the segments layout, 0 without one
+func (n Network) members() int {
+	members := 0
+	for _, m := range n.Segments {
+		members += m
+	}
+	return members
+}
+
+func (n Network) wan() bool {
+	return n.RttMs >= networkWanRtt
+}
+
+// rttSeconds returns rtts round trips in seconds, rounded up
+func (n Network) rttSeconds(rtts float64) int64 {
+	return int64(math.Ceil(n.RttMs * rtts / 1000))
+}
+
+// networkProviderParams adds gmcast.segment when the request has a segments layout
+func (c *Configurator) networkProviderParams() {
+	n := c.request.Network
+	if n == nil || len(n.Segments) == 0 {
+		return
+	}
+	c.providerParams["gmcast.segment"] = ProviderParam{"gmcast.segment", strconv.Itoa(n.Segment), -1, 0, 0, 0}
+}
+
+// scaleProviderByNetwork raises the timeouts to the round trips they must cover and the send windows to the messages in flight
+func (c *Configurator) scaleProviderByNetwork(key string, value int64) int64 {
+	n := c.request.Network
+	if n == nil {
+		return value
+	}
+	var floor int64
+	switch key {
+	case "evs.suspect_timeout":
+		floor = n.rttSeconds(networkSuspectRtts)
+	case "evs.inactive_timeout":
+		floor = 2 * n.rttSeconds(networkSuspectRtts)
+	case "gmcast.peer_timeout":
+		floor = n.rttSeconds(networkPeerTimeoutRtts)
+	case "evs.send_window":
+		floor = int64(math.Ceil(n.RttMs * networkWindowPerRttMs))
+	case "evs.user_send_window":
+		floor = int64(math.Ceil(n.RttMs * networkWindowPerRttMs / 2))
+	default:
+		return value
+	}
+	if param := c.providerParams[key]; floor > param.RMax && param.RMax > 0 {
+		floor = param.RMax
+	}
+	if floor > value {
+		return floor
+	}
+	return value
+}
+
+// networkMessageMaxSize caps the group replication message on a WAN, a large transaction does not hold the link
+func (c *Configurator) networkMessageMaxSize(parameter Parameter) Parameter {
+	if n := c.request.Network; n == nil || !n.wan() {
+		return parameter
+	}
+	if v, err := strconv.ParseInt(parameter.Value, 10, 64); err == nil && (v == 0 || v > networkWanMaxMessage) {
+		c.branch("capped on WAN")
+		parameter.Value = strconv.Itoa(networkWanMaxMessage)
+	}
+	return parameter
+}
+
+// networkExpelTimeout returns the seconds of round trips a member may miss before it is expelled, 0 without a network
+func (c *Configurator) networkExpelTimeout() int {
+	if c.request.Network == nil {
+		return 0
+	}
+	return int(c.request.Network.rttSeconds(networkSuspectRtts))
+}
+
+func (c *Configurator) getGroupReplicationNetwork() {
+	n := c.request.Network
+	if n == nil {
+		return
+	}
+	group := c.families[FamilyTypeMysql].Groups["configuration_groupReplication"]
+	expel, _ := strconv.Atoi(group.Parameters["loose_group_replication_member_expel_timeout"].Value)
+	c.traced(group, "loose_group_replication_unreachable_majority_timeout",
+		func(p Parameter) Parameter { return c.paramGroupReplicationUnreachableMajorityTimeout(p, expel) },
+		"max × loadFactor plus twice the suspicion and member_expel_timeout, within min and max", traceLoadFactor, traceRtt)
+	c.families[FamilyTypeMysql].Groups["configuration_groupReplication"] = group
+}
+
+// groupReplicationSuspicion is the seconds group replication waits before it suspects a member
+const groupReplicationSuspicion = 5
+
+// getNetworkReport returns the commit penalty of the round trip: Galera and group replication wait for the
+// group on every commit, async replication does not
+func (c *Configurator) getNetworkReport() *NetworkReport {
+	n := c.request.Network
+	report := &NetworkReport{RttMs: n.RttMs, Wan: n.wan()}
+	if c.request.DBType == DbTypeAsync {
+		report.Notes = append(report.Notes, fmt.Sprintf("async replication does not wait for the replicas, they lag at least %.1fms behind the source", n.RttMs/2))
+	} else {
+		report.CommitPenaltyMs = n.RttMs
+		report.MaxCommitsPerConnection = int(math.Floor(1000 / n.RttMs))
+		report.Notes = append(report.Notes, fmt.Sprintf("every commit waits one round trip for the group, +%.1fms, a connection committing serially reaches at most %d commits/sec",
+			n.RttMs, report.MaxCommitsPerConnection))
+	}
+	for i, members := range n.Segments {
+		if 2*members >= c.request.clusterNodes() && len(n.Segments) > 1 {
+			report.Notes = append(report.Notes, fmt.Sprintf("segment %d holds %d of %d members, losing it loses the quorum", i, members, c.request.clusterNodes()))
+		}
+	}
+	return report
+}
+
+func (r *NetworkReport) text() string {
+	text := fmt.Sprintf("\nNetwork round trip = %.1fms\n", r.RttMs)
+	for _, note := range r.Notes {
+		text += note + "\n"
+	}
+	return text
+}
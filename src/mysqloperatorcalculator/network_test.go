@@ -0,0 +1,106 @@
+package mysqloperatorcalculator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNetwork_Calculate(t *testing.T) {
+	// Galera across three datacenters
+	_, _, families := runCalculate(makeRequest(DbTypePXC, 4, LoadTypeSomeWrites, 200))
+	base := splitProviderOptions(families[FamilyTypeMysql].Groups["configuration_galera"].Parameters["wsrep-provider-options"].Value)
+	req := makeRequest(DbTypePXC, 4, LoadTypeSomeWrites, 200)
+	req.Network = &Network{RttMs: 80, Segments: []int{2, 2, 1}, Segment: 1}
+	err, msg, families := runCalculate(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	wan := splitProviderOptions(families[FamilyTypeMysql].Groups["configuration_galera"].Parameters["wsrep-provider-options"].Value)
+	// 80ms round trips: 100 of them before suspecting a member, 30 for a peer and 4 messages in flight per ms
+	for key, want := range map[string]string{
+		"gmcast.segment":       "1",
+		"evs.suspect_timeout":  "PT8S",
+		"evs.inactive_timeout": "PT16S",
+		"gmcast.peer_timeout":  "PT3S",
+		"evs.send_window":      "320",
+		"evs.user_send_window": "160",
+	} {
+		if wan[key] != want {
+			t.Errorf("%s = %s, want %s", key, wan[key], want)
+		}
+	}
+	if _, ok := base["gmcast.segment"]; ok {
+		t.Error("gmcast.segment must only be set with segments")
+	}
+	if report := messageReport[*NetworkReport](msg, ReportNetwork); report == nil || report.CommitPenaltyMs != 80 || report.MaxCommitsPerConnection != 12 || !strings.Contains(msg.MText, "+80.0ms") {
+		t.Errorf("network report = %+v", report)
+	}
+	b, err := (&MysqlOperatorCalculator{}).GetPXCCROutput(msg, req, families)
+	if err != nil || !strings.Contains(b.String(), "size: 5\n") {
+		t.Errorf("the segments must give the 5 members to the custom resource: %v", err)
+	}
+
+	// Group replication on a WAN
+	req = makeRequest(DbTypeGroupReplication, 4, LoadTypeSomeWrites, 200)
+	req.Network = &Network{RttMs: 120, Segments: []int{2, 1}}
+	_, msg, families = runCalculate(req)
+	gr := families[FamilyTypeMysql].Groups["configuration_groupReplication"].Parameters
+	for name, want := range map[string]string{
+		"loose_group_replication_member_expel_timeout":           "12",
+		"loose_group_replication_communication_max_message_size": "2097152",
+		"loose_group_replication_paxos_single_leader":            "ON",
+		"loose_group_replication_unreachable_majority_timeout":   "192",
+	} {
+		if v := gr[name].Value; v != want {
+			t.Errorf("%s = %s, want %s", name, v, want)
+		}
+	}
+	if notes := messageReport[*NetworkReport](msg, ReportNetwork).Notes; len(notes) != 2 {
+		t.Errorf("the segment of 2 of 3 members must be reported as holding the quorum: %v", notes)
+	}
+	req.Network.RttMs = 300
+	_, _, families = runCalculate(req)
+	if v := families[FamilyTypeMysql].Groups["configuration_groupReplication"].Parameters["loose_group_replication_unreachable_majority_timeout"].Value; v != "228" {
+		t.Errorf("unreachable_majority_timeout = %s, must grow with the round trip", v)
+	}
+	req.Mode = ModeMultiPrimary
+	_, _, families = runCalculate(req)
+	if v := families[FamilyTypeMysql].Groups["configuration_groupReplication"].Parameters["loose_group_replication_paxos_single_leader"].Value; v != "OFF" {
+		t.Errorf("multi-primary has no single leader: %s", v)
+	}
+	req.Network = nil
+	_, _, families = runCalculate(req)
+	if v := families[FamilyTypeMysql].Groups["configuration_groupReplication"].Parameters["loose_group_replication_paxos_single_leader"].Value; v != "OFF" {
+		t.Errorf("paxos_single_leader follows the mode without a network: %s", v)
+	}
+	_, _, families = runCalculate(makeRequest(DbTypeGroupReplication, 4, LoadTypeSomeWrites, 200))
+	if _, ok := families[FamilyTypeMysql].Groups["configuration_groupReplication"].Parameters["loose_group_replication_unreachable_majority_timeout"]; ok {
+		t.Error("unreachable_majority_timeout must only be set with a network")
+	}
+
+	// Async replicas lag, commits do not wait
+	req = makeRequest(DbTypeAsync, 4, LoadTypeSomeWrites, 200)
+	req.Network = &Network{RttMs: 40}
+	_, msg, _ = runCalculate(req)
+	if report := messageReport[*NetworkReport](msg, ReportNetwork); report == nil || report.CommitPenaltyMs != 0 {
+		t.Errorf("async commits do not wait for the replicas: %+v", report)
+	}
+
+	for name, n := range map[string]Network{
+		"no rtt":        {Segments: []int{2, 1}},
+		"empty segment": {RttMs: 10, Segments: []int{3, 0}},
+		"out of range":  {RttMs: 10, Segments: []int{2, 1}, Segment: 2},
+		"no layout":     {RttMs: 10, Segment: 1},
+		"even members":  {RttMs: 10, Segments: []int{2, 2}},
+		"nodes differ":  {RttMs: 10, Segments: []int{2, 1}},
+	} {
+		req := makeRequest(DbTypePXC, 4, LoadTypeSomeWrites, 100)
+		req.Network = &n
+		if name == "nodes differ" {
+			req.Nodes = 5
+		}
+		if err, _, _ := runCalculate(req); err == nil {
+			t.Errorf("%s: expected a network error", name)
+		}
+	}
+}
@@ -0,0 +1,316 @@
+package mysqloperatorcalculator
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+type crMetadata struct {
+	Name string `yaml:"name"`
+}
+
+type crResourceList struct {
+	Memory string `yaml:"memory"`
+	Cpu    string `yaml:"cpu"`
+}
+
+type crResources struct {
+	Requests crResourceList `yaml:"requests"`
+	Limits   crResourceList `yaml:"limits"`
+}
+
+type crProbe struct {
+	TimeoutSeconds int `yaml:"timeoutSeconds"`
+}
+
+type crVolumeSpec struct {
+	PersistentVolumeClaim struct {
+		Resources struct {
+			Requests struct {
+				Storage string `yaml:"storage"`
+			} `yaml:"requests"`
+		} `yaml:"resources"`
+	} `yaml:"persistentVolumeClaim"`
+}
+
+type crPmm struct {
+	Enabled    bool        `yaml:"enabled"`
+	Image      string      `yaml:"image"`
+	ServerHost string      `yaml:"serverHost"`
+	Resources  crResources `yaml:"resources"`
+}
+
+type crDisabled struct {
+	Enabled bool `yaml:"enabled"`
+}
+
+type pxcCRNode struct {
+	Size            int          `yaml:"size"`
+	Image           string       `yaml:"image"`
+	AutoRecovery    bool         `yaml:"autoRecovery"`
+	Configuration   string       `yaml:"configuration"`
+	Resources       crResources  `yaml:"resources"`
+	ReadinessProbes crProbe      `yaml:"readinessProbes"`
+	LivenessProbes  crProbe      `yaml:"livenessProbes"`
+	VolumeSpec      crVolumeSpec `yaml:"volumeSpec"`
+}
+
+// pxcCRProxy is used for both haproxy and proxysql, a disabled proxy only renders enabled: false.
+// Configuration carries the haproxy.cfg or proxysql.cnf of the proxy group
+type pxcCRProxy struct {
+	Enabled         bool        `yaml:"enabled"`
+	Size            int         `yaml:"size,omitempty"`
+	Image           string      `yaml:"image,omitempty"`
+	Configuration   string      `yaml:"configuration,omitempty"`
+	Resources       crResources `yaml:"resources,omitempty"`
+	ReadinessProbes crProbe     `yaml:"readinessProbes,omitempty"`
+	LivenessProbes  crProbe     `yaml:"livenessProbes,omitempty"`
+}
+
+type pxcCRSpec struct {
+	CrVersion   string     `yaml:"crVersion"`
+	SecretsName string     `yaml:"secretsName"`
+	Pxc         pxcCRNode  `yaml:"pxc"`
+	HAProxy     pxcCRProxy `yaml:"haproxy"`
+	ProxySQL    pxcCRProxy `yaml:"proxysql"`
+	Pmm         crPmm      `yaml:"pmm"`
+}
+
+type pxcCR struct {
+	ApiVersion string     `yaml:"apiVersion"`
+	Kind       string     `yaml:"kind"`
+	Metadata   crMetadata `yaml:"metadata"`
+	Spec       pxcCRSpec  `yaml:"spec"`
+}
+
+// GetPXCCROutput renders the mysql, proxy and monitor families as a PerconaXtraDBCluster custom resource
+func (moc *MysqlOperatorCalculator) GetPXCCROutput(message ResponseMessage, request ConfigurationRequest, families map[string]Family) (bytes.Buffer, error) {
+	var b bytes.Buffer
+
+	if request.DBType != DbTypePXC {
+		return b, fmt.Errorf("PerconaXtraDBCluster custom resource requires dbtype %s, got %q", DbTypePXC, request.DBType)
+	}
+	mysql, ok := families[FamilyTypeMysql]
+	if !ok {
+		return b, errors.New("no mysql configuration to render, see message for details")
+	}
+	proxy := families[FamilyTypeProxy]
+	monitor := families[FamilyTypeMonitor]
+
+	var cr pxcCR
+	cr.ApiVersion = PXCCRApiVersion
+	cr.Kind = PXCCRKind
+	cr.Metadata.Name = CRDefaultName
+	cr.Spec.CrVersion = PXCCRVersion
+	cr.Spec.SecretsName = CRDefaultName + "-secrets"
+
+	configuration := mysql.ParseGroupsMysqld("")
+	cr.Spec.Pxc = pxcCRNode{
+		Size:            request.clusterNodes(),
+		Image:           fmt.Sprintf("%s:%d.%d", PXCCRImage, request.Mysqlversion.Major, request.Mysqlversion.Minor),
+		AutoRecovery:    true,
+		Configuration:   configuration.String(),
+		Resources:       mysql.crResources(),
+		ReadinessProbes: mysql.crProbe("readinessProbe"),
+		LivenessProbes:  mysql.crProbe("livenessProbe"),
+	}
+	cr.Spec.Pxc.VolumeSpec.PersistentVolumeClaim.Resources.Requests.Storage = crStorage(message)
+
+	proxySpec := pxcCRProxy{
+		Enabled:         true,
+		Size:            DefaultClusterNodes,
+		Image:           CRHAProxyImage,
+		Resources:       proxy.crResources(),
+		ReadinessProbes: proxy.crProbe("readinessProbe"),
+		LivenessProbes:  proxy.crProbe("livenessProbe"),
+	}
+	if request.ProxyType == ProxyTypeProxySQL {
+		proxysql, err := proxysqlCfg(families)
+		if err != nil {
+			return b, err
+		}
+		proxySpec.Image = CRProxySQLImage
+		proxySpec.Configuration = proxysql.String()
+		cr.Spec.ProxySQL = proxySpec
+	} else {
+		haproxy, err := haproxyCfg(request, families)
+		if err != nil {
+			return b, err
+		}
+		proxySpec.Configuration = haproxy.String()
+		cr.Spec.HAProxy = proxySpec
+	}
+	cr.Spec.Pmm = crPmm{
+		Enabled:    true,
+		Image:      CRPmmClientImage,
+		ServerHost: CRPmmServerHost,
+		Resources:  monitor.crResources(),
+	}
+
+	writeCRHeader(&b, cr.Kind, message)
+	err := encodeCR(&b, cr)
+	return b, err
+}
+
+type psCRMySQL struct {
+	ClusterType    string       `yaml:"clusterType"`
+	Size           int          `yaml:"size"`
+	Image          string       `yaml:"image"`
+	Configuration  string       `yaml:"configuration"`
+	Resources      crResources  `yaml:"resources"`
+	ReadinessProbe crProbe      `yaml:"readinessProbe"`
+	LivenessProbe  crProbe      `yaml:"livenessProbe"`
+	VolumeSpec     crVolumeSpec `yaml:"volumeSpec"`
+}
+
+// psCRProxyService is used for both haproxy and router, a disabled service only renders enabled: false.
+// Configuration carries the haproxy.cfg stanzas or the mysqlrouter.conf sections of the proxy group
+type psCRProxyService struct {
+	Enabled        bool        `yaml:"enabled"`
+	Size           int         `yaml:"size,omitempty"`
+	Image          string      `yaml:"image,omitempty"`
+	Configuration  string      `yaml:"configuration,omitempty"`
+	Resources      crResources `yaml:"resources,omitempty"`
+	ReadinessProbe crProbe     `yaml:"readinessProbe,omitempty"`
+	LivenessProbe  crProbe     `yaml:"livenessProbe,omitempty"`
+}
+
+type psCRProxy struct {
+	HAProxy psCRProxyService `yaml:"haproxy"`
+	Router  psCRProxyService `yaml:"router"`
+}
+
+type psCRSpec struct {
+	CrVersion    string     `yaml:"crVersion"`
+	SecretsName  string     `yaml:"secretsName"`
+	MySQL        psCRMySQL  `yaml:"mysql"`
+	Proxy        psCRProxy  `yaml:"proxy"`
+	Orchestrator crDisabled `yaml:"orchestrator"`
+	Pmm          crPmm      `yaml:"pmm"`
+}
+
+type psCR struct {
+	ApiVersion string     `yaml:"apiVersion"`
+	Kind       string     `yaml:"kind"`
+	Metadata   crMetadata `yaml:"metadata"`
+	Spec       psCRSpec   `yaml:"spec"`
+}
+
+// GetPSCROutput renders the mysql, proxy and monitor families as a PerconaServerMySQL custom resource
+// using group replication
+func (moc *MysqlOperatorCalculator) GetPSCROutput(message ResponseMessage, request ConfigurationRequest, families map[string]Family) (bytes.Buffer, error) {
+	var b bytes.Buffer
+
+	if request.DBType != DbTypeGroupReplication {
+		return b, fmt.Errorf("PerconaServerMySQL custom resource requires dbtype %s, got %q", DbTypeGroupReplication, request.DBType)
+	}
+	if request.ProxyType == ProxyTypeProxySQL {
+		return b, fmt.Errorf("PerconaServerMySQL custom resource does not support proxytype %s", ProxyTypeProxySQL)
+	}
+	mysql, ok := families[FamilyTypeMysql]
+	if !ok {
+		return b, errors.New("no mysql configuration to render, see message for details")
+	}
+	proxy := families[FamilyTypeProxy]
+	monitor := families[FamilyTypeMonitor]
+
+	var cr psCR
+	cr.ApiVersion = PSCRApiVersion
+	cr.Kind = PSCRKind
+	cr.Metadata.Name = CRDefaultName
+	cr.Spec.CrVersion = PSCRVersion
+	cr.Spec.SecretsName = CRDefaultName + "-secrets"
+
+	configuration := mysql.ParseGroupsMysqld("")
+	cr.Spec.MySQL = psCRMySQL{
+		ClusterType:    PSCRClusterType,
+		Size:           request.clusterNodes(),
+		Image:          fmt.Sprintf("%s:%d.%d", PSCRImage, request.Mysqlversion.Major, request.Mysqlversion.Minor),
+		Configuration:  configuration.String(),
+		Resources:      mysql.crResources(),
+		ReadinessProbe: mysql.crProbe("readinessProbe"),
+		LivenessProbe:  mysql.crProbe("livenessProbe"),
+	}
+	cr.Spec.MySQL.VolumeSpec.PersistentVolumeClaim.Resources.Requests.Storage = crStorage(message)
+
+	proxySpec := psCRProxyService{
+		Enabled:        true,
+		Size:           DefaultClusterNodes,
+		Image:          CRHAProxyImage,
+		Resources:      proxy.crResources(),
+		ReadinessProbe: proxy.crProbe("readinessProbe"),
+		LivenessProbe:  proxy.crProbe("livenessProbe"),
+	}
+	if request.ProxyType == ProxyTypeRouter {
+		router, err := routerCfg(families)
+		if err != nil {
+			return b, err
+		}
+		proxySpec.Image = fmt.Sprintf("%s:%d.%d", CRRouterImage, request.Mysqlversion.Major, request.Mysqlversion.Minor)
+		proxySpec.Configuration = router.String()
+		cr.Spec.Proxy.Router = proxySpec
+	} else {
+		haproxy, err := haproxyCfg(request, families)
+		if err != nil {
+			return b, err
+		}
+		proxySpec.Configuration = haproxy.String()
+		cr.Spec.Proxy.HAProxy = proxySpec
+	}
+	cr.Spec.Pmm = crPmm{
+		Enabled:    true,
+		Image:      CRPmmClientImage,
+		ServerHost: CRPmmServerHost,
+		Resources:  monitor.crResources(),
+	}
+
+	writeCRHeader(&b, cr.Kind, message)
+	err := encodeCR(&b, cr)
+	return b, err
+}
+
+// crStorage returns the recommended PVC size, the default volume when the request has no storage
+func crStorage(message ResponseMessage) string {
+	if storage, ok := message.Reports[ReportStorage].(*StorageReport); ok && storage.PVC != "" {
+		return storage.PVC
+	}
+	return CRDefaultStorage
+}
+
+// writeCRHeader keeps the diagnostic message as comments so the document stays applicable as is
+func writeCRHeader(b *bytes.Buffer, kind string, message ResponseMessage) {
+	fmt.Fprintf(b, "# %s generated by mysqloperatorcalculator\n", kind)
+	fmt.Fprintf(b, "# message: %d %s\n", message.MType, message.MName)
+}
+
+func encodeCR(b *bytes.Buffer, cr interface{}) error {
+	encoder := yaml.NewEncoder(b)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(cr); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+// crResources maps the resources group of the family onto a Kubernetes requests/limits block
+func (f Family) crResources() crResources {
+	params := f.Groups["resources"].Parameters
+	return crResources{
+		Requests: crResourceList{Memory: params["request_memory"].Value, Cpu: params["request_cpu"].Value},
+		Limits:   crResourceList{Memory: params["limit_memory"].Value, Cpu: params["limit_cpu"].Value},
+	}
+}
+
+// crProbe returns the probe timeout of the family, falling back to the parameter default
+func (f Family) crProbe(group string) crProbe {
+	parameter := f.Groups[group].Parameters["timeoutSeconds"]
+	timeout, err := strconv.Atoi(parameter.Value)
+	if err != nil {
+		timeout, _ = strconv.Atoi(parameter.Default)
+	}
+	return crProbe{TimeoutSeconds: timeout}
+}
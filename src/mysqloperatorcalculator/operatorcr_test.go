@@ -0,0 +1,182 @@
+package mysqloperatorcalculator
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Run `go test ./... -update` to regenerate the golden files after an intended change.
+var updateGolden = flag.Bool("update", false, "rewrite golden files under testdata")
+
+// compareGolden checks got against testdata/<name>, rewriting the file when -update is set.
+func compareGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *updateGolden {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("cannot read golden file %s: %v (run with -update to create it)", path, err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("output differs from %s (run with -update to accept)\n--- got ---\n%s", path, got)
+	}
+}
+
+// predefinedDimensions returns the dimensions of the built-in table, skipping the open sentinels.
+func predefinedDimensions() []Dimension {
+	var conf Configuration
+	conf.Init()
+	var dims []Dimension
+	for _, dim := range conf.Dimension {
+		if dim.Id == DimensionOpen || dim.Id == ConnectionDimension {
+			continue
+		}
+		dims = append(dims, dim)
+	}
+	return dims
+}
+
+func TestPXCCROutput_Golden(t *testing.T) {
+	for _, dim := range predefinedDimensions() {
+		t.Run(dim.Name, func(t *testing.T) {
+			req := makeRequest(DbTypePXC, dim.Id, LoadTypeSomeWrites, 100)
+			err, msg, families := runCalculate(req)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var moc MysqlOperatorCalculator
+			b, err := moc.GetPXCCROutput(msg, req, families)
+			if err != nil {
+				t.Fatalf("GetPXCCROutput: %v", err)
+			}
+			compareGolden(t, filepath.Join("pxc_cr", strings.ToLower(dim.Name)+".yaml"), b.Bytes())
+		})
+	}
+}
+
+func TestPXCCROutput_RejectsGroupReplication(t *testing.T) {
+	req := makeRequest(DbTypeGroupReplication, 2, LoadTypeSomeWrites, 100)
+	_, msg, families := runCalculate(req)
+
+	var moc MysqlOperatorCalculator
+	if _, err := moc.GetPXCCROutput(msg, req, families); err == nil {
+		t.Error("expected error rendering a PerconaXtraDBCluster for group_replication, got nil")
+	}
+}
+
+func TestPXCCROutput_EmptyFamilies(t *testing.T) {
+	var moc MysqlOperatorCalculator
+	req := makeRequest(DbTypePXC, 1, LoadTypeSomeWrites, 100)
+	if _, err := moc.GetPXCCROutput(ResponseMessage{MType: OverutilizingI}, req, map[string]Family{}); err == nil {
+		t.Error("expected error when there is no mysql family to render, got nil")
+	}
+}
+
+func TestPSCROutput_Golden(t *testing.T) {
+	for _, dim := range predefinedDimensions() {
+		t.Run(dim.Name, func(t *testing.T) {
+			req := makeRequest(DbTypeGroupReplication, dim.Id, LoadTypeSomeWrites, 100)
+			err, msg, families := runCalculate(req)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var moc MysqlOperatorCalculator
+			b, err := moc.GetPSCROutput(msg, req, families)
+			if err != nil {
+				t.Fatalf("GetPSCROutput: %v", err)
+			}
+			if !strings.Contains(b.String(), "clusterType: "+PSCRClusterType) {
+				t.Errorf("spec.mysql.clusterType %q missing", PSCRClusterType)
+			}
+			compareGolden(t, filepath.Join("ps_cr", strings.ToLower(dim.Name)+".yaml"), b.Bytes())
+		})
+	}
+}
+
+func TestPSCROutput_RejectsPXC(t *testing.T) {
+	req := makeRequest(DbTypePXC, 2, LoadTypeSomeWrites, 100)
+	_, msg, families := runCalculate(req)
+
+	var moc MysqlOperatorCalculator
+	if _, err := moc.GetPSCROutput(msg, req, families); err == nil {
+		t.Error("expected error rendering a PerconaServerMySQL for pxc, got nil")
+	}
+}
+
+func TestPXCCROutput_ProxySQL(t *testing.T) {
+	req := makeRequest(DbTypePXC, 3, LoadTypeSomeWrites, 100)
+	req.ProxyType = ProxyTypeProxySQL
+	_, msg, families := runCalculate(req)
+
+	var moc MysqlOperatorCalculator
+	b, err := moc.GetPXCCROutput(msg, req, families)
+	if err != nil {
+		t.Fatalf("GetPXCCROutput: %v", err)
+	}
+	out := b.String()
+	if !strings.Contains(out, "  haproxy:\n    enabled: false\n") {
+		t.Errorf("haproxy must be disabled when proxysql is selected\n%s", out)
+	}
+	if !strings.Contains(out, "  proxysql:\n    enabled: true\n") || !strings.Contains(out, "image: "+CRProxySQLImage) {
+		t.Errorf("proxysql must be enabled with image %s\n%s", CRProxySQLImage, out)
+	}
+	params := families[FamilyTypeProxy].Groups[GroupNameProxySQL].Parameters
+	for _, want := range []string{
+		"    configuration: |\n      datadir=\"/var/lib/proxysql\"\n",
+		"      mysql_variables =\n      {\n",
+		fmt.Sprintf("        max_connections=%d\n", 100*DefaultClusterNodes),
+		"        threads=" + params["mysql-threads"].Value + "\n",
+		"        connect_timeout_server=" + params["mysql-connect_timeout_server"].Value + "\n",
+		"      # mysql_servers max_connections=" + params["mysql_servers.max_connections"].Value + "\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("proxysql configuration missing %q\n%s", want, out)
+		}
+	}
+}
+
+func TestPSCROutput_RejectsProxySQL(t *testing.T) {
+	req := makeRequest(DbTypeGroupReplication, 2, LoadTypeSomeWrites, 100)
+	req.ProxyType = ProxyTypeProxySQL
+	_, msg, families := runCalculate(req)
+
+	var moc MysqlOperatorCalculator
+	if _, err := moc.GetPSCROutput(msg, req, families); err == nil {
+		t.Error("expected error rendering a PerconaServerMySQL with proxysql, got nil")
+	}
+}
+
+func TestPSCROutput_Router(t *testing.T) {
+	req := makeRequest(DbTypeGroupReplication, 3, LoadTypeSomeWrites, 100)
+	req.ProxyType = ProxyTypeRouter
+	_, msg, families := runCalculate(req)
+
+	var moc MysqlOperatorCalculator
+	b, err := moc.GetPSCROutput(msg, req, families)
+	if err != nil {
+		t.Fatalf("GetPSCROutput: %v", err)
+	}
+	out := b.String()
+	if !strings.Contains(out, "    haproxy:\n      enabled: false\n") {
+		t.Errorf("haproxy must be disabled when router is selected\n%s", out)
+	}
+	if !strings.Contains(out, "    router:\n      enabled: true\n") || !strings.Contains(out, "image: "+CRRouterImage+":8.0") {
+		t.Errorf("router must be enabled with image %s:8.0\n%s", CRRouterImage, out)
+	}
+	compareGolden(t, filepath.Join("ps_cr", "router.yaml"), b.Bytes())
+}
@@ -0,0 +1,195 @@
+package mysqloperatorcalculator
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/go-version"
+)
+
+const rulePinned = "pinned by the request"
+
+// notOverridable are the mysql groups that describe the pod, not the server configuration
+var notOverridable = map[string]bool{
+	"resources":      true,
+	"readinessProbe": true,
+	"livenessProbe":  true,
+}
+
+// validateOverrides checks every pinned value exists for the request and is inside the parameter Min/Max and version range
+func validateOverrides(request ConfigurationRequest, families map[string]Family) error {
+	keys := make([]string, 0, len(request.Overrides))
+	for k := range request.Overrides {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	providerParams := providerDefaults()
+
+	for _, key := range keys {
+		value := request.Overrides[key]
+		if pParam, ok := providerParams[key]; ok {
+			if request.DBType != DbTypePXC {
+				return fmt.Errorf("Override %s is a wsrep provider option, only valid with DB Type %s", key, DbTypePXC)
+			}
+			if err := validateProviderOverride(pParam, value); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if key == "wsrep-provider-options" {
+			return fmt.Errorf("Override %s cannot be pinned as a whole, pin the single provider options instead (e.g. gcache.size)", key)
+		}
+
+		parameter, ok := lookupOverridable(families, key)
+		if !ok {
+			return fmt.Errorf("Override %s is not a parameter the calculator manages for DB Type %s", key, request.DBType)
+		}
+		if !parameterInVersion(parameter, request.Mysqlversion) {
+			return fmt.Errorf("Override %s is only valid from MySQL %d.%d.%d to %d.%d.%d", key,
+				parameter.Mysqlversions.Min.Major, parameter.Mysqlversions.Min.Minor, parameter.Mysqlversions.Min.Patch,
+				parameter.Mysqlversions.Max.Major, parameter.Mysqlversions.Max.Minor, parameter.Mysqlversions.Max.Patch)
+		}
+		if err := validateParameterOverride(parameter, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// enumeratedOverrides are the parameters that only take some values, mysqld compares them case insensitive
+var enumeratedOverrides = map[string][]string{
+	"loose_group_replication_consistency":                      consistencyLevels,
+	"loose_group_replication_paxos_single_leader":              {"ON", "OFF"},
+	"loose_group_replication_single_primary_mode":              {"ON", "OFF"},
+	"loose_group_replication_enforce_update_everywhere_checks": {"ON", "OFF"},
+}
+
+// validateParameterOverride checks the enumerated parameters are one of their values and the bounds of numeric parameters,
+// the others (methods, lists) are taken as they are
+func validateParameterOverride(parameter Parameter, value string) error {
+	if values, ok := enumeratedOverrides[parameter.Name]; ok {
+		for _, v := range values {
+			if strings.EqualFold(v, value) {
+				return nil
+			}
+		}
+		return fmt.Errorf("Override %s=%s is not one of %s", parameter.Name, value, strings.Join(values, ", "))
+	}
+	if _, err := strconv.ParseInt(parameter.Default, 10, 64); err != nil {
+		return nil
+	}
+	v, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return fmt.Errorf("Override %s must be a positive integer, got %q", parameter.Name, value)
+	}
+	if kind, detail := parameterBounds(parameter, v); kind != "" {
+		return errors.New("Override " + detail)
+	}
+	return nil
+}
+
+func validateProviderOverride(pParam ProviderParam, value string) error {
+	// Value -1 marks the options with a fixed literal (pc.recovery=true)
+	if pParam.Value < 0 {
+		return fmt.Errorf("Override %s is fixed to %s and cannot be pinned", pParam.Name, pParam.Literal)
+	}
+	v, err := pParam.parse(value)
+	if err != nil {
+		return err
+	}
+	if pParam.RMax > 0 && (v < pParam.RMin || v > pParam.RMax) {
+		if unit := pParam.unit(); unit != "" {
+			return fmt.Errorf("Override %s=%s is outside the range %s-%s", pParam.Name, value, pParam.format(pParam.RMin), pParam.format(pParam.RMax))
+		}
+		return fmt.Errorf("Override %s=%d is outside the range %d-%d", pParam.Name, v, pParam.RMin, pParam.RMax)
+	}
+	return nil
+}
+
+func lookupOverridable(families map[string]Family, name string) (Parameter, bool) {
+	for groupName, group := range families[FamilyTypeMysql].Groups {
+		if notOverridable[groupName] {
+			continue
+		}
+		if parameter, ok := group.Parameters[name]; ok {
+			return parameter, true
+		}
+	}
+	return Parameter{}, false
+}
+
+// parameterInVersion reports if the parameter exists in the given MySQL version, parameters without a range exist in all
+func parameterInVersion(parameter Parameter, v Version) bool {
+	if parameter.Mysqlversions.Min.Major == 0 {
+		return true
+	}
+	incomingV, _ := version.NewVersion(fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch))
+	paramVmin, _ := version.NewVersion(fmt.Sprintf("%d.%d.%d", parameter.Mysqlversions.Min.Major, parameter.Mysqlversions.Min.Minor, parameter.Mysqlversions.Min.Patch))
+	paramVmax, _ := version.NewVersion(fmt.Sprintf("%d.%d.%d", parameter.Mysqlversions.Max.Major, parameter.Mysqlversions.Max.Minor, parameter.Mysqlversions.Max.Patch))
+
+	return !incomingV.LessThan(paramVmin) && !incomingV.GreaterThan(paramVmax)
+}
+
+// pinned returns the value the request pinned for the parameter
+func (c *Configurator) pinned(name string) (string, bool) {
+	value, ok := c.request.Overrides[name]
+	return value, ok
+}
+
+// pinnedProviderOptions returns the provider options the request pinned, sorted by name
+func (c *Configurator) pinnedProviderOptions() []string {
+	var options []string
+	for key := range c.providerParams {
+		if _, ok := c.pinned(key); ok {
+			options = append(options, key)
+		}
+	}
+	sort.Strings(options)
+	return options
+}
+
+func (c *Configurator) pinnedInt(name string) (int64, bool) {
+	value, ok := c.pinned(name)
+	if !ok {
+		return 0, false
+	}
+	v, err := strconv.ParseInt(value, 10, 64)
+	return v, err == nil
+}
+
+// pin replaces the value of the parameter key with the pinned one and marks it as pinned
+func (c *Configurator) pin(group GroupObj, key string) {
+	parameter := group.Parameters[key]
+	value, ok := c.pinned(parameter.Name)
+	if !ok {
+		return
+	}
+	parameter.Value = value
+	group.Parameters[key] = parameter
+	trace := &ParameterTrace{Pinned: true, Replaced: parameter.Default}
+	if c.request.Explain {
+		trace.Rule = rulePinned
+	}
+	group.Traces[key] = trace
+}
+
+// applyOverrides pins the values before any rule runs, parameters no rule sets (tmp_table_size) keep them as they are.
+// Only the mysql family is pinned, the proxy options with the same name (router max_connections) are calculated
+func (c *Configurator) applyOverrides() {
+	if len(c.request.Overrides) == 0 {
+		return
+	}
+	for groupName, group := range c.families[FamilyTypeMysql].Groups {
+		if notOverridable[groupName] {
+			continue
+		}
+		for key := range group.Parameters {
+			c.pin(group, key)
+		}
+	}
+}
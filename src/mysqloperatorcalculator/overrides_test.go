@@ -0,0 +1,149 @@
+package mysqloperatorcalculator
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestOverrides(t *testing.T) {
+	base := makeRequest(DbTypePXC, 4, LoadTypeSomeWrites, 200)
+	err, baseMsg, _ := runCalculate(base)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	req := makeRequest(DbTypePXC, 4, LoadTypeSomeWrites, 200)
+	req.Overrides = map[string]string{
+		"innodb_flush_log_at_trx_commit": "1",
+		"tmp_table_size":                 "67108864",
+		"gcache.size":                    "1073741824",
+	}
+	err, msg, families := runCalculate(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	innodb := families[FamilyTypeMysql].Groups["configuration_innodb"]
+	connection := families[FamilyTypeMysql].Groups["configuration_connection"]
+	if p := innodb.Parameters["innodb_flush_log_at_trx_commit"]; p.Value != "1" || !innodb.IsPinned("innodb_flush_log_at_trx_commit") {
+		t.Errorf("innodb_flush_log_at_trx_commit must be pinned to 1, got %q pinned=%v", p.Value, innodb.IsPinned("innodb_flush_log_at_trx_commit"))
+	}
+	if p := connection.Parameters["tmp_table_size"]; p.Value != "67108864" || !connection.IsPinned("tmp_table_size") {
+		t.Errorf("tmp_table_size must be pinned, got %q pinned=%v", p.Value, connection.IsPinned("tmp_table_size"))
+	}
+	if innodb.IsPinned("innodb_buffer_pool_size") {
+		t.Error("innodb_buffer_pool_size is calculated, not pinned")
+	}
+
+	// The pinned values are in the memory accounting
+	if msg.Breakdown.Memory.Mysql.TmpTableFootprint <= baseMsg.Breakdown.Memory.Mysql.TmpTableFootprint {
+		t.Errorf("tmp table footprint must grow with the pinned tmp_table_size: %d <= %d",
+			msg.Breakdown.Memory.Mysql.TmpTableFootprint, baseMsg.Breakdown.Memory.Mysql.TmpTableFootprint)
+	}
+	if msg.Breakdown.Memory.Mysql.Gcache != 1073741824 {
+		t.Errorf("gcache must be the pinned value, got %d", msg.Breakdown.Memory.Mysql.Gcache)
+	}
+	if !strings.Contains(families[FamilyTypeMysql].Groups["configuration_galera"].Parameters["wsrep-provider-options"].Value, "gcache.size=1073741824;") {
+		t.Error("wsrep-provider-options must carry the pinned gcache.size")
+	}
+	if msg.Breakdown.Memory.Mysql.BufferPool >= baseMsg.Breakdown.Memory.Mysql.BufferPool {
+		t.Errorf("buffer pool must shrink around the larger pinned consumers: %d >= %d",
+			msg.Breakdown.Memory.Mysql.BufferPool, baseMsg.Breakdown.Memory.Mysql.BufferPool)
+	}
+
+	var moc MysqlOperatorCalculator
+	b, err := moc.GetJSONOutput(msg, req, families)
+	if err != nil || !strings.Contains(b.String(), `"pinned": true`) || strings.Contains(b.String(), `"explain"`) {
+		t.Errorf("JSON output must flag the pinned values without traces, err=%v", err)
+	}
+	if !strings.Contains(b.String(), `"pinnedOptions": [`) {
+		t.Error("JSON output must flag the pinned provider options of wsrep-provider-options")
+	}
+	var payload struct {
+		Request struct {
+			Answer map[string]struct {
+				Groups map[string]struct {
+					Parameters map[string]parameterOutput `json:"parameters"`
+				} `json:"groups"`
+			} `json:"answer"`
+		} `json:"request"`
+	}
+	if err := json.Unmarshal(b.Bytes(), &payload); err != nil {
+		t.Fatalf("JSON output: %v", err)
+	}
+	options := payload.Request.Answer[FamilyTypeMysql].Groups["configuration_galera"].Parameters["wsrep-provider-options"].Options
+	if o := options["gcache.size"]; o.Value != "1073741824" || !o.Pinned || options["gcs.fc_limit"].Pinned {
+		t.Errorf("JSON output must carry the provider options under wsrep-provider-options, gcache.size pinned: %+v", options)
+	}
+	if h, _ := moc.GetHumanOutput(msg, req, families); !strings.Contains(h.String(), "# pinned gcache.size") {
+		t.Error("human output must flag the pinned provider options of wsrep-provider-options")
+	}
+
+	// Only the mysql family is pinned, the router options named max_connections are calculated
+	req = makeRequest(DbTypeGroupReplication, 4, LoadTypeSomeWrites, 200)
+	req.ProxyType = ProxyTypeRouter
+	req.Overrides = map[string]string{"max_connections": "431"}
+	err, _, families = runCalculate(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if g := families[FamilyTypeMysql].Groups["configuration_server"]; g.Parameters["max_connections"].Value != "431" || !g.IsPinned("max_connections") {
+		t.Errorf("mysql max_connections must be pinned to 431, got %q", g.Parameters["max_connections"].Value)
+	}
+	router := families[FamilyTypeProxy].Groups["routerConfig"]
+	for _, key := range []string{"routing_rw.max_connections", "routing_ro.max_connections"} {
+		if router.Parameters[key].Value == "431" || router.IsPinned(key) {
+			t.Errorf("%s must be calculated, got %q pinned=%v", key, router.Parameters[key].Value, router.IsPinned(key))
+		}
+	}
+
+	// A pinned buffer pool is kept as it is, one larger than the memory cannot be balanced
+	req = makeRequest(DbTypePXC, 4, LoadTypeSomeWrites, 200)
+	req.Overrides = map[string]string{"innodb_buffer_pool_size": "4294967296"}
+	err, msg, families = runCalculate(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if v := families[FamilyTypeMysql].Groups["configuration_innodb"].Parameters["innodb_buffer_pool_size"].Value; v != "4294967296" {
+		t.Errorf("innodb_buffer_pool_size must stay pinned, got %s", v)
+	}
+	if msg.Breakdown.Memory.Mysql.BufferPool != 4294967296 {
+		t.Errorf("breakdown must report the pinned buffer pool, got %d", msg.Breakdown.Memory.Mysql.BufferPool)
+	}
+	req.Overrides = map[string]string{"innodb_buffer_pool_size": "68719476736"}
+	if err, _, _ = runCalculate(req); err == nil {
+		t.Error("a pinned buffer pool above the mysql memory must be reported as overutilizing")
+	}
+}
+
+func TestOverridesErrors(t *testing.T) {
+	cases := []struct {
+		name      string
+		dbtype    string
+		version   Version
+		overrides map[string]string
+	}{
+		{"unknown parameter", DbTypePXC, Version{}, map[string]string{"not_a_variable": "1"}},
+		{"pod resources", DbTypePXC, Version{}, map[string]string{"request_memory": "4"}},
+		{"below min", DbTypePXC, Version{}, map[string]string{"tmp_table_size": "1024"}},
+		{"above max", DbTypePXC, Version{}, map[string]string{"innodb_flush_log_at_trx_commit": "3"}},
+		{"not a number", DbTypePXC, Version{}, map[string]string{"max_connections": "many"}},
+		{"version", DbTypePXC, Version{Major: 8, Minor: 0, Patch: 44}, map[string]string{"tmp_table_size": "67108864"}},
+		{"provider option on GR", DbTypeGroupReplication, Version{}, map[string]string{"gcache.size": "1073741824"}},
+		{"provider option range", DbTypePXC, Version{}, map[string]string{"gcs.fc_limit": "1000"}},
+		{"whole provider options", DbTypePXC, Version{}, map[string]string{"wsrep-provider-options": "gcache.size=1G"}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := makeRequest(tc.dbtype, 4, LoadTypeSomeWrites, 200)
+			if tc.version.Major > 0 {
+				req.Mysqlversion = tc.version
+			}
+			req.Overrides = tc.overrides
+			if err, _, _ := runCalculate(req); err == nil {
+				t.Errorf("expected an error for %v", tc.overrides)
+			}
+		})
+	}
+}
@@ -0,0 +1,201 @@
+package mysqloperatorcalculator
+
+import (
+	"math"
+	"sort"
+
+	"code.cloudfoundry.org/bytefmt"
+)
+
+type ProviderProfile struct {
+	Name            string  `json:"name"`
+	DefaultMaxPods  int     `json:"defaultMaxPods"`
+	EvictionMemory  float64 `json:"evictionMemory"`
+	DaemonSetCpu    int     `json:"daemonSetCpu"`
+	DaemonSetMemory float64 `json:"daemonSetMemory"`
+	reservedCpu     func(cpu int) int
+	reservedMemory  func(memory float64, maxPods int) float64
+}
+
+type ResourceDeduction struct {
+	Name        string  `json:"name"`
+	Cpu         int     `json:"cpu"`
+	Memory      string  `json:"memory"`
+	MemoryBytes float64 `json:"memoryBytes"`
+}
+
+type ProviderDeductions struct {
+	Provider          string              `json:"provider"`
+	MaxPods           int                 `json:"maxPods"`
+	NodeCpu           int                 `json:"nodeCpu"`
+	NodeMemory        float64             `json:"nodeMemory"`
+	AllocatableCpu    int                 `json:"allocatableCpu"`
+	AllocatableMemory float64             `json:"allocatableMemory"`
+	Deductions        []ResourceDeduction `json:"deductions"`
+}
+
+// providerProfiles CPU in millicores, memory in bytes. DaemonSet values are the requests of what
+// the platform installs on every node (CNI, kube-proxy, CSI node driver, logging/metrics agents)
+var providerProfiles = map[string]ProviderProfile{
+	ProviderEKS: {
+		Name:            ProviderEKS,
+		DefaultMaxPods:  110,
+		EvictionMemory:  EvictionHardMemory,
+		DaemonSetCpu:    150,
+		DaemonSetMemory: 256 * mib,
+		reservedCpu:     tieredCpuReservation,
+		// 255Mi plus 11Mi for every pod the node can host
+		reservedMemory: func(memory float64, maxPods int) float64 {
+			return float64(255+11*maxPods) * mib
+		},
+	},
+	ProviderGKE: {
+		Name:            ProviderGKE,
+		DefaultMaxPods:  110,
+		EvictionMemory:  EvictionHardMemory,
+		DaemonSetCpu:    250,
+		DaemonSetMemory: 384 * mib,
+		reservedCpu:     tieredCpuReservation,
+		reservedMemory:  tieredMemoryReservation,
+	},
+	ProviderAKS: {
+		Name:            ProviderAKS,
+		DefaultMaxPods:  110,
+		EvictionMemory:  EvictionHardMemory,
+		DaemonSetCpu:    200,
+		DaemonSetMemory: 320 * mib,
+		reservedCpu:     aksCpuReservation,
+		// 20Mi per pod plus 50Mi, never more than 25% of the node
+		reservedMemory: func(memory float64, maxPods int) float64 {
+			return math.Min(float64(50+20*maxPods)*mib, memory*0.25)
+		},
+	},
+	ProviderOpenShift: {
+		Name:            ProviderOpenShift,
+		DefaultMaxPods:  250,
+		EvictionMemory:  EvictionHardMemory,
+		DaemonSetCpu:    300,
+		DaemonSetMemory: 1024 * mib,
+		reservedCpu:     tieredCpuReservation,
+		reservedMemory:  tieredMemoryReservation,
+	},
+	// kubeadm defaults: nothing reserved for kube and system, only the eviction threshold
+	ProviderBareMetal: {
+		Name:            ProviderBareMetal,
+		DefaultMaxPods:  110,
+		EvictionMemory:  EvictionHardMemory,
+		DaemonSetCpu:    100,
+		DaemonSetMemory: 128 * mib,
+		reservedCpu:     func(cpu int) int { return 0 },
+		reservedMemory:  func(memory float64, maxPods int) float64 { return 0 },
+	},
+}
+
+// GetProviderProfile returns the named provider profile
+func GetProviderProfile(name string) (ProviderProfile, bool) {
+	profile, ok := providerProfiles[name]
+	return profile, ok
+}
+
+// providerNames returns the profile names in alphabetical order
+func providerNames() []string {
+	names := make([]string, 0, len(providerProfiles))
+	for name := range providerProfiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Deduct computes what the platform keeps on a node with the given CPU (millicores), memory (bytes)
+// and pod density. maxPods <= 0 uses the profile default
+func (p ProviderProfile) Deduct(cpu int, memory float64, maxPods int) ProviderDeductions {
+	if maxPods <= 0 {
+		maxPods = p.DefaultMaxPods
+	}
+	deductions := []ResourceDeduction{
+		newResourceDeduction(DeductionKubeReserved, p.reservedCpu(cpu), p.reservedMemory(memory, maxPods)),
+		newResourceDeduction(DeductionEviction, 0, p.EvictionMemory),
+		newResourceDeduction(DeductionDaemonSets, p.DaemonSetCpu, p.DaemonSetMemory),
+	}
+
+	result := ProviderDeductions{
+		Provider:          p.Name,
+		MaxPods:           maxPods,
+		NodeCpu:           cpu,
+		NodeMemory:        memory,
+		AllocatableCpu:    cpu,
+		AllocatableMemory: memory,
+		Deductions:        deductions,
+	}
+	for _, d := range deductions {
+		result.AllocatableCpu -= d.Cpu
+		result.AllocatableMemory -= d.MemoryBytes
+	}
+	return result
+}
+
+// newResourceDeduction rounds memory up to whole bytes so allocatable values stay integral
+func newResourceDeduction(name string, cpu int, memory float64) ResourceDeduction {
+	memory = math.Ceil(memory)
+	return ResourceDeduction{Name: name, Cpu: cpu, Memory: bytefmt.ByteSize(uint64(memory)), MemoryBytes: memory}
+}
+
+// tieredCpuReservation follows the tiers GKE, EKS and OpenShift document: 6% of the first core, 1% of the second,
+// 0.5% of the next two and 0.25% of every core above four
+func tieredCpuReservation(cpu int) int {
+	return int(math.Ceil(tieredReservation(float64(cpu), []reservationTier{
+		{1000, 0.06},
+		{2000, 0.01},
+		{4000, 0.005},
+		{0, 0.0025},
+	})))
+}
+
+// tieredMemoryReservation follows the tiers GKE and OpenShift document: 25% of the first 4GiB, 20% of the next 4GiB,
+// 10% of the next 8GiB, 6% of the next 112GiB and 2% of everything above 128GiB
+func tieredMemoryReservation(memory float64, maxPods int) float64 {
+	return tieredReservation(memory, []reservationTier{
+		{4 * gib, 0.25},
+		{8 * gib, 0.20},
+		{16 * gib, 0.10},
+		{128 * gib, 0.06},
+		{0, 0.02},
+	})
+}
+
+// aksCpuReservation uses the AKS table, a node takes the value of the largest size not above its core count
+func aksCpuReservation(cpu int) int {
+	table := []struct{ cores, reserved int }{
+		{1, 60}, {2, 100}, {4, 140}, {8, 180}, {16, 260}, {32, 420}, {64, 740},
+	}
+	reserved := table[0].reserved
+	for _, row := range table {
+		if cpu >= row.cores*1000 {
+			reserved = row.reserved
+		}
+	}
+	return reserved
+}
+
+// reservationTier reserves pct of the amount up to upTo, 0 means no upper bound
+type reservationTier struct {
+	upTo float64
+	pct  float64
+}
+
+func tieredReservation(total float64, tiers []reservationTier) float64 {
+	reserved := 0.0
+	lower := 0.0
+	for _, tier := range tiers {
+		upper := tier.upTo
+		if upper == 0 || upper > total {
+			upper = total
+		}
+		if upper > lower {
+			reserved += (upper - lower) * tier.pct
+			lower = upper
+		}
+	}
+	return reserved
+}
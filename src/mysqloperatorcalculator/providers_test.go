@@ -0,0 +1,89 @@
+package mysqloperatorcalculator
+
+import (
+	"testing"
+)
+
+func TestTieredReservation(t *testing.T) {
+	tiers := []reservationTier{{1000, 0.06}, {2000, 0.01}, {4000, 0.005}, {0, 0.0025}}
+	cases := map[float64]float64{
+		500:  30,
+		1000: 60,
+		2000: 70,
+		4000: 80,
+		8000: 90,
+	}
+	for total, want := range cases {
+		if got := tieredReservation(total, tiers); got != want {
+			t.Errorf("tieredReservation(%.0f) = %f, want %f", total, got, want)
+		}
+	}
+}
+
+func TestTieredMemoryReservation(t *testing.T) {
+	// 25% of 4GiB + 20% of 4GiB + 10% of 8GiB + 6% of 16GiB
+	want := 0.25*4*gib + 0.20*4*gib + 0.10*8*gib + 0.06*16*gib
+	if got := tieredMemoryReservation(32*gib, 0); got != want {
+		t.Errorf("tieredMemoryReservation(32GiB) = %.0f, want %.0f", got, want)
+	}
+}
+
+func TestAKSCpuReservation(t *testing.T) {
+	cases := map[int]int{500: 60, 1000: 60, 3000: 100, 8000: 180, 12000: 180, 96000: 740}
+	for cpu, want := range cases {
+		if got := aksCpuReservation(cpu); got != want {
+			t.Errorf("aksCpuReservation(%d) = %d, want %d", cpu, got, want)
+		}
+	}
+}
+
+// AKS caps the kube-reserved memory at 25% of the node
+func TestProviderProfile_AKSMemoryCap(t *testing.T) {
+	profile, _ := GetProviderProfile(ProviderAKS)
+	d := profile.Deduct(2000, 2*testGB, 110)
+	if got := d.Deductions[0].MemoryBytes; got != 2*testGB*0.25 {
+		t.Errorf("kube-reserved memory = %.0f, want %.0f", got, 2*testGB*0.25)
+	}
+}
+
+// Every profile must deduct something, report the three deductions and keep the totals consistent
+func TestProviderProfiles_Deduct(t *testing.T) {
+	for _, name := range providerNames() {
+		t.Run(name, func(t *testing.T) {
+			profile, ok := GetProviderProfile(name)
+			if !ok {
+				t.Fatalf("profile %s missing", name)
+			}
+			d := profile.Deduct(8000, 32*testGB, 0)
+			if d.Provider != name || d.MaxPods != profile.DefaultMaxPods {
+				t.Errorf("unexpected provider/maxPods %s/%d", d.Provider, d.MaxPods)
+			}
+			if len(d.Deductions) != 3 {
+				t.Fatalf("expected 3 deductions, got %d", len(d.Deductions))
+			}
+			cpu, memory := 0, 0.0
+			for _, ded := range d.Deductions {
+				cpu += ded.Cpu
+				memory += ded.MemoryBytes
+			}
+			if d.AllocatableCpu != 8000-cpu || d.AllocatableMemory != 32*testGB-memory {
+				t.Errorf("allocatable %d/%.0f does not match the deductions %d/%.0f", d.AllocatableCpu, d.AllocatableMemory, cpu, memory)
+			}
+			if cpu <= 0 || memory <= 0 || d.AllocatableCpu <= 0 || d.AllocatableMemory <= 0 {
+				t.Errorf("deductions out of range: %+v", d)
+			}
+		})
+	}
+}
+
+// bare-metal keeps only the eviction threshold and the CNI/kube-proxy DaemonSets
+func TestProviderProfile_BareMetal(t *testing.T) {
+	profile, _ := GetProviderProfile(ProviderBareMetal)
+	d := profile.Deduct(4000, 16*testGB, 0)
+	if d.Deductions[0].Cpu != 0 || d.Deductions[0].MemoryBytes != 0 {
+		t.Errorf("bare-metal should not reserve kube resources, got %+v", d.Deductions[0])
+	}
+	if d.AllocatableCpu != 4000-profile.DaemonSetCpu {
+		t.Errorf("allocatable cpu = %d, want %d", d.AllocatableCpu, 4000-profile.DaemonSetCpu)
+	}
+}
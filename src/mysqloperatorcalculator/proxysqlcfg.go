@@ -0,0 +1,57 @@
+package mysqloperatorcalculator
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// proxysqlVariablePrefix is the prefix of the proxysqlConfig parameters set in mysql_variables
+const proxysqlVariablePrefix = "mysql-"
+
+// proxysqlCfg returns the proxysql.cnf of the proxysqlConfig group: the admin interface the operator expects and
+// the mysql_variables calculated for the pod. The operator replaces the admin credentials with the ones of its secret
+func proxysqlCfg(families map[string]Family) (bytes.Buffer, error) {
+	var b bytes.Buffer
+
+	proxy, ok := families[FamilyTypeProxy]
+	if !ok {
+		return b, errors.New("no proxy configuration to render, see message for details")
+	}
+	proxysql, ok := proxy.Groups[GroupNameProxySQL]
+	if !ok {
+		return b, fmt.Errorf("proxy family %s has no %s group", proxy.Name, GroupNameProxySQL)
+	}
+
+	b.WriteString("datadir=\"/var/lib/proxysql\"\n")
+	b.WriteString("\n")
+	b.WriteString("admin_variables =\n{\n")
+	b.WriteString("  admin_credentials=\"proxyadmin:admin_password\"\n")
+	b.WriteString("  mysql_ifaces=\"0.0.0.0:6032\"\n")
+	b.WriteString("  refresh_interval=2000\n")
+	b.WriteString("}\n")
+	b.WriteString("\n")
+
+	var names []string
+	for name := range proxysql.Parameters {
+		if strings.HasPrefix(name, proxysqlVariablePrefix) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	b.WriteString("mysql_variables =\n{\n")
+	b.WriteString("  interfaces=\"0.0.0.0:3306\"\n")
+	for _, name := range names {
+		fmt.Fprintf(&b, "  %s=%s\n", strings.TrimPrefix(name, proxysqlVariablePrefix), proxysql.Parameters[name].Value)
+	}
+	b.WriteString("}\n")
+
+	// The operator adds the servers at runtime, their limit is only given as a hint
+	if servers, ok := proxysql.Parameters["mysql_servers.max_connections"]; ok {
+		fmt.Fprintf(&b, "\n# mysql_servers max_connections=%s\n", servers.Value)
+	}
+	return b, nil
+}
@@ -0,0 +1,54 @@
+package mysqloperatorcalculator
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// routerDefaultSection is the mysqlrouter.conf section written first, its options apply to every route
+const routerDefaultSection = "DEFAULT"
+
+// routerCfg returns the mysqlrouter.conf sections of the routerConfig group, each option in the section its
+// parameter names
+func routerCfg(families map[string]Family) (bytes.Buffer, error) {
+	var b bytes.Buffer
+
+	proxy, ok := families[FamilyTypeProxy]
+	if !ok {
+		return b, errors.New("no proxy configuration to render, see message for details")
+	}
+	router, ok := proxy.Groups[GroupNameRouter]
+	if !ok {
+		return b, fmt.Errorf("proxy family %s has no %s group", proxy.Name, GroupNameRouter)
+	}
+
+	sections := make(map[string][]Parameter)
+	for _, parameter := range router.Parameters {
+		sections[parameter.Section] = append(sections[parameter.Section], parameter)
+	}
+	var names []string
+	for section := range sections {
+		if section != routerDefaultSection {
+			names = append(names, section)
+		}
+	}
+	sort.Strings(names)
+	if _, ok := sections[routerDefaultSection]; ok {
+		names = append([]string{routerDefaultSection}, names...)
+	}
+
+	for i, section := range names {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		parameters := sections[section]
+		sort.Slice(parameters, func(i, j int) bool { return parameters[i].Name < parameters[j].Name })
+		fmt.Fprintf(&b, "[%s]\n", section)
+		for _, parameter := range parameters {
+			fmt.Fprintf(&b, "%s=%s\n", parameter.Name, parameter.Value)
+		}
+	}
+	return b, nil
+}
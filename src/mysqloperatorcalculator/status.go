@@ -0,0 +1,307 @@
+package mysqloperatorcalculator
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// loadTypeWriteRatios is the share of writes each load type stands for [MostlyReads, SomeWrites, EqualReadsWrites, HeavyWrites]
+var loadTypeWriteRatios = [4]float64{0.05, 0.20, 0.50, 0.90}
+
+// statusWriteCounters are the Com_ counters of the statements that change data
+var statusWriteCounters = []string{
+	"com_insert", "com_insert_select", "com_update", "com_update_multi",
+	"com_delete", "com_delete_multi", "com_replace", "com_replace_select", "com_load",
+}
+
+// tmpTableTiers is the tmp_table_size pinned by the share of temporary tables created on disk, the first matching tier wins
+var tmpTableTiers = []struct {
+	diskPct float64
+	size    uint64
+}{
+	{0.25, 134217728},
+	{0.10, 67108864},
+	{0.05, 33554432},
+}
+
+// Names of the values derived from a snapshot
+const (
+	StatusWriteRatio       = "writeRatio"
+	StatusLoadType         = "loadtype"
+	StatusConnections      = "connections"
+	StatusTmpTableSize     = "tmp_table_size"
+	StatusBufferPoolMisses = "bufferPoolMissPct"
+)
+
+type StatusDerivation struct {
+	Name   string            `json:"name"`
+	Value  string            `json:"value"`
+	Rule   string            `json:"rule"`
+	Inputs map[string]string `json:"inputs,omitempty"`
+	// Applied is false when the request already set the value
+	Applied bool `json:"applied"`
+}
+
+type StatusProfile struct {
+	Reads             uint64             `json:"reads"`
+	Writes            uint64             `json:"writes"`
+	WriteRatio        float64            `json:"writeRatio"`
+	LoadTypeId        int                `json:"loadTypeId"`
+	PeakConnections   int                `json:"peakConnections"`
+	TmpDiskPct        float64            `json:"tmpDiskPct"`
+	TmpTableSize      uint64             `json:"tmpTableSize,omitempty"`
+	BufferPoolMissPct float64            `json:"bufferPoolMissPct"`
+	Derivations       []StatusDerivation `json:"derivations"`
+}
+
+// ParseGlobalStatus reads a SHOW GLOBAL STATUS / VARIABLES capture and returns the values by lower case name.
+// It accepts the tab separated batch output (mysql -B), the table output (| name | value |), a JSON object
+// of name/value and a JSON array of {"Variable_name", "Value"} rows
+func ParseGlobalStatus(r io.Reader) (map[string]string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	trimmed := strings.TrimSpace(string(data))
+	switch {
+	case trimmed == "":
+		return nil, errors.New("empty global status snapshot")
+	case strings.HasPrefix(trimmed, "{"):
+		return parseGlobalStatusObject([]byte(trimmed))
+	case strings.HasPrefix(trimmed, "["):
+		return parseGlobalStatusRows([]byte(trimmed))
+	default:
+		return parseGlobalStatusText(trimmed)
+	}
+}
+
+func parseGlobalStatusObject(data []byte) (map[string]string, error) {
+	var object map[string]interface{}
+	if err := json.Unmarshal(data, &object); err != nil {
+		return nil, fmt.Errorf("global status snapshot: %v", err)
+	}
+	status := make(map[string]string, len(object))
+	for name, value := range object {
+		status[strings.ToLower(name)] = fmt.Sprint(value)
+	}
+	return status, nil
+}
+
+func parseGlobalStatusRows(data []byte) (map[string]string, error) {
+	var rows []map[string]interface{}
+	if err := json.Unmarshal(data, &rows); err != nil {
+		return nil, fmt.Errorf("global status snapshot: %v", err)
+	}
+	status := make(map[string]string, len(rows))
+	for i, row := range rows {
+		var name, value string
+		for key, v := range row {
+			switch strings.ToLower(key) {
+			case "variable_name":
+				name = fmt.Sprint(v)
+			case "value":
+				value = fmt.Sprint(v)
+			}
+		}
+		if name == "" {
+			return nil, fmt.Errorf("global status snapshot: row %d has no Variable_name", i+1)
+		}
+		status[strings.ToLower(name)] = value
+	}
+	return status, nil
+}
+
+func parseGlobalStatusText(data string) (map[string]string, error) {
+	status := make(map[string]string)
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "+") || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		var fields []string
+		if strings.HasPrefix(line, "|") {
+			for _, field := range strings.Split(strings.Trim(line, "|"), "|") {
+				fields = append(fields, strings.TrimSpace(field))
+			}
+		} else {
+			fields = strings.Fields(line)
+		}
+
+		name := strings.ToLower(fields[0])
+		if name == "variable_name" {
+			continue
+		}
+		if len(fields) > 1 {
+			status[name] = fields[1]
+		} else {
+			status[name] = ""
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+// DeriveStatusProfile computes the workload profile of a snapshot, the Com_ counters are required
+func DeriveStatusProfile(status map[string]string) (StatusProfile, error) {
+	var profile StatusProfile
+
+	reads, ok := statusCounter(status, "com_select")
+	inputs := map[string]string{"Com_select": strconv.FormatUint(reads, 10)}
+	for _, name := range statusWriteCounters {
+		if v, found := statusCounter(status, name); found {
+			profile.Writes += v
+			inputs[statusName(name)] = strconv.FormatUint(v, 10)
+			ok = true
+		}
+	}
+	profile.Reads = reads
+	if !ok || profile.Reads+profile.Writes == 0 {
+		return profile, errors.New("the global status snapshot has no Com_select/Com_insert/Com_update/Com_delete counters")
+	}
+
+	profile.WriteRatio = float64(profile.Writes) / float64(profile.Reads+profile.Writes)
+	profile.derive(StatusWriteRatio, strconv.FormatFloat(profile.WriteRatio, 'f', 4, 64),
+		"writes / (Com_select + writes), writes are the Com_ insert, update, delete, replace and load counters", inputs)
+
+	profile.LoadTypeId = nearestLoadType(profile.WriteRatio)
+	profile.derive(StatusLoadType, strconv.Itoa(profile.LoadTypeId),
+		"load type with the closest share of writes (5%, 20%, 50%, 90%)",
+		map[string]string{StatusWriteRatio: strconv.FormatFloat(profile.WriteRatio, 'f', 4, 64)})
+
+	if peak, found := statusCounter(status, "max_used_connections"); found {
+		profile.PeakConnections = int(peak)
+		profile.derive(StatusConnections, strconv.Itoa(profile.PeakConnections), "Max_used_connections, the peak since the server started",
+			map[string]string{"Max_used_connections": strconv.FormatUint(peak, 10)})
+	} else if current, found := statusCounter(status, "threads_connected"); found {
+		profile.PeakConnections = int(current)
+		profile.derive(StatusConnections, strconv.Itoa(profile.PeakConnections), "Threads_connected, no peak in the snapshot",
+			map[string]string{"Threads_connected": strconv.FormatUint(current, 10)})
+	}
+
+	tmpTables, _ := statusCounter(status, "created_tmp_tables")
+	tmpDiskTables, _ := statusCounter(status, "created_tmp_disk_tables")
+	if tmpTables > 0 {
+		profile.TmpDiskPct = float64(tmpDiskTables) / float64(tmpTables)
+		rule := "share of temporary tables on disk below 5%, calculated value kept"
+		for _, tier := range tmpTableTiers {
+			if profile.TmpDiskPct > tier.diskPct {
+				profile.TmpTableSize = tier.size
+				rule = fmt.Sprintf("share of temporary tables on disk above %.0f%%", tier.diskPct*100)
+				break
+			}
+		}
+		size := ""
+		if profile.TmpTableSize > 0 {
+			size = strconv.FormatUint(profile.TmpTableSize, 10)
+		}
+		profile.derive(StatusTmpTableSize, size, rule, map[string]string{
+			"Created_tmp_tables":      strconv.FormatUint(tmpTables, 10),
+			"Created_tmp_disk_tables": strconv.FormatUint(tmpDiskTables, 10),
+		})
+	}
+
+	readRequests, _ := statusCounter(status, "innodb_buffer_pool_read_requests")
+	diskReads, _ := statusCounter(status, "innodb_buffer_pool_reads")
+	if readRequests > 0 {
+		profile.BufferPoolMissPct = float64(diskReads) / float64(readRequests)
+		profile.derive(StatusBufferPoolMisses, strconv.FormatFloat(profile.BufferPoolMissPct, 'f', 4, 64),
+			"Innodb_buffer_pool_reads / Innodb_buffer_pool_read_requests, reported only", map[string]string{
+				"Innodb_buffer_pool_reads":         strconv.FormatUint(diskReads, 10),
+				"Innodb_buffer_pool_read_requests": strconv.FormatUint(readRequests, 10),
+			})
+	}
+
+	return profile, nil
+}
+
+// apply fills the request values left unset: write ratio and load type, connections. The tmp_table_size is set by the
+// Configurator from the profile, unless the request pins it
+func (profile *StatusProfile) apply(request *ConfigurationRequest) {
+	loadUnset := request.LoadType.Id == 0 && request.WriteRatio == nil
+	for i := range profile.Derivations {
+		d := &profile.Derivations[i]
+		switch d.Name {
+		case StatusWriteRatio:
+			if loadUnset {
+				ratio := profile.WriteRatio
+				request.WriteRatio = &ratio
+				d.Applied = true
+			}
+		case StatusLoadType:
+			if loadUnset {
+				request.LoadType.Id = profile.LoadTypeId
+				d.Applied = true
+			}
+		case StatusConnections:
+			if request.Connections == 0 && profile.PeakConnections > 0 {
+				request.Connections = profile.PeakConnections
+				d.Applied = true
+			}
+		case StatusTmpTableSize:
+			if _, pinned := request.Overrides[StatusTmpTableSize]; !pinned && profile.TmpTableSize > 0 {
+				d.Applied = true
+			}
+		}
+	}
+}
+
+// getStatusTmpTableSize sets the tmp_table_size of the snapshot, temporary tables going to disk need a larger one
+func (c *Configurator) getStatusTmpTableSize(group GroupObj) {
+	profile := c.request.StatusProfile
+	if profile == nil || profile.TmpTableSize == 0 {
+		return
+	}
+	c.traced(group, "tmp_table_size",
+		func(p Parameter) Parameter {
+			p.Value = strconv.FormatUint(profile.TmpTableSize, 10)
+			return p
+		}, "by the share of temporary tables created on disk in the global status", traceTmpDiskPct)
+}
+
+func (profile *StatusProfile) derive(name string, value string, rule string, inputs map[string]string) {
+	profile.Derivations = append(profile.Derivations, StatusDerivation{Name: name, Value: value, Rule: rule, Inputs: inputs})
+}
+
+// profileFromGlobalStatus parses and derives the snapshot of a request
+func profileFromGlobalStatus(snapshot string) (StatusProfile, error) {
+	status, err := ParseGlobalStatus(strings.NewReader(snapshot))
+	if err != nil {
+		return StatusProfile{}, err
+	}
+	return DeriveStatusProfile(status)
+}
+
+// nearestLoadType returns the load type whose share of writes is the closest to writeRatio
+func nearestLoadType(writeRatio float64) int {
+	loadType := LoadTypeMostlyReads
+	for i, ratio := range loadTypeWriteRatios {
+		if math.Abs(writeRatio-ratio) < math.Abs(writeRatio-loadTypeWriteRatios[loadType-1]) {
+			loadType = i + 1
+		}
+	}
+	return loadType
+}
+
+func statusCounter(status map[string]string, name string) (uint64, bool) {
+	value, ok := status[name]
+	if !ok {
+		return 0, false
+	}
+	v, err := strconv.ParseUint(value, 10, 64)
+	return v, err == nil
+}
+
+// statusName returns the counter as SHOW GLOBAL STATUS names it (Com_insert)
+func statusName(name string) string {
+	return strings.ToUpper(name[:1]) + name[1:]
+}
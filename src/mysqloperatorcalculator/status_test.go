@@ -0,0 +1,136 @@
+package mysqloperatorcalculator
+
+import (
+	"strings"
+	"testing"
+)
+
+const testGlobalStatus = `Variable_name	Value
+Com_select	700
+Com_insert	200
+Com_update	80
+Com_delete	20
+Max_used_connections	340
+Threads_connected	120
+Created_tmp_tables	1000
+Created_tmp_disk_tables	150
+Innodb_buffer_pool_read_requests	100000
+Innodb_buffer_pool_reads	500
+innodb_buffer_pool_size	8589934592
+`
+
+func TestParseGlobalStatus_Formats(t *testing.T) {
+	formats := map[string]string{
+		"batch": testGlobalStatus,
+		"table": `+---------------+-------+
+| Variable_name | Value |
++---------------+-------+
+| Com_select    | 700   |
+| Com_insert    | 300   |
+| Ssl_cipher    |       |
++---------------+-------+`,
+		"json object": `{"Com_select": 700, "Com_insert": "300"}`,
+		"json rows":   `[{"Variable_name": "Com_select", "Value": "700"}, {"Variable_name": "Com_insert", "Value": "300"}]`,
+	}
+	for name, snapshot := range formats {
+		t.Run(name, func(t *testing.T) {
+			status, err := ParseGlobalStatus(strings.NewReader(snapshot))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if status["com_select"] != "700" {
+				t.Errorf("com_select = %q, want 700", status["com_select"])
+			}
+		})
+	}
+
+	for _, snapshot := range []string{"", `{"Com_select": `, `[{"Value": "1"}]`} {
+		if _, err := ParseGlobalStatus(strings.NewReader(snapshot)); err == nil {
+			t.Errorf("expected an error for %q", snapshot)
+		}
+	}
+}
+
+func TestDeriveStatusProfile(t *testing.T) {
+	status, _ := ParseGlobalStatus(strings.NewReader(testGlobalStatus))
+	profile, err := DeriveStatusProfile(status)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if profile.Writes != 300 || profile.WriteRatio != 0.3 {
+		t.Errorf("writes %d ratio %.2f, want 300 and 0.30", profile.Writes, profile.WriteRatio)
+	}
+	if profile.LoadTypeId != LoadTypeSomeWrites {
+		t.Errorf("30%% writes must pick load type %d, got %d", LoadTypeSomeWrites, profile.LoadTypeId)
+	}
+	if profile.PeakConnections != 340 {
+		t.Errorf("peak connections %d, want Max_used_connections 340", profile.PeakConnections)
+	}
+	if profile.TmpTableSize != 67108864 {
+		t.Errorf("15%% temporary tables on disk must pin 64MiB, got %d", profile.TmpTableSize)
+	}
+	for _, d := range profile.Derivations {
+		if d.Rule == "" || len(d.Inputs) == 0 {
+			t.Errorf("%s: derivation misses rule or inputs %+v", d.Name, d)
+		}
+	}
+
+	for ratio, want := range map[float64]int{0.0: 1, 0.12: 1, 0.13: 2, 0.36: 3, 0.69: 3, 0.71: 4, 1.0: 4} {
+		if got := nearestLoadType(ratio); got != want {
+			t.Errorf("nearestLoadType(%.2f) = %d, want %d", ratio, got, want)
+		}
+	}
+
+	if _, err := DeriveStatusProfile(map[string]string{"uptime": "10"}); err == nil {
+		t.Error("a snapshot without Com_ counters must be rejected")
+	}
+}
+
+func TestGlobalStatus_Request(t *testing.T) {
+	req := makeRequest(DbTypePXC, 4, 0, 0)
+	req.GlobalStatus = testGlobalStatus
+	var conf Configuration
+	conf.Init()
+	var moc MysqlOperatorCalculator
+	incoming := moc.Init(req, conf)
+	if incoming.StatusProfile == nil || incoming.GlobalStatus != "" {
+		t.Fatal("Init must replace the snapshot with the status profile")
+	}
+	if incoming.WriteRatio == nil || incoming.LoadType.Id != LoadTypeSomeWrites || incoming.LoadType.Name == "" || incoming.Connections != 340 {
+		t.Errorf("load type %+v and connections %d must come from the snapshot", incoming.LoadType, incoming.Connections)
+	}
+
+	err, _, families := moc.GetCalculate()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if g := families[FamilyTypeMysql].Groups["configuration_connection"]; g.Parameters["tmp_table_size"].Value != "67108864" || g.IsPinned("tmp_table_size") {
+		t.Errorf("tmp_table_size must be calculated from the temporary tables on disk, not pinned, got %q", g.Parameters["tmp_table_size"].Value)
+	}
+	if len(moc.IncomingRequest.Overrides) != 0 {
+		t.Errorf("the snapshot must not add overrides to the request: %v", moc.IncomingRequest.Overrides)
+	}
+
+	// The request values win over the snapshot
+	req = makeRequest(DbTypePXC, 4, LoadTypeHeavyWrites, 100)
+	req.GlobalStatus = testGlobalStatus
+	req.Overrides = map[string]string{"tmp_table_size": "33554432"}
+	incoming = moc.Init(req, conf)
+	if incoming.LoadType.Id != LoadTypeHeavyWrites || incoming.Connections != 100 || incoming.Overrides["tmp_table_size"] != "33554432" {
+		t.Errorf("request values must be kept: %+v %d %v", incoming.LoadType, incoming.Connections, incoming.Overrides)
+	}
+	for _, d := range incoming.StatusProfile.Derivations {
+		if d.Applied {
+			t.Errorf("%s must not be applied when the request sets it", d.Name)
+		}
+	}
+	if _, _, families = moc.GetCalculate(); families[FamilyTypeMysql].Groups["configuration_connection"].Parameters["tmp_table_size"].Value != "33554432" {
+		t.Error("a pinned tmp_table_size must win over the snapshot")
+	}
+
+	req = makeRequest(DbTypePXC, 4, LoadTypeSomeWrites, 100)
+	req.GlobalStatus = "Uptime\t100\n"
+	if err, _, _ := runCalculate(req); err == nil || !strings.Contains(err.Error(), "Com_") {
+		t.Errorf("a snapshot without counters must be reported, got %v", err)
+	}
+}
@@ -0,0 +1,206 @@
+package mysqloperatorcalculator
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+const (
+	// storageGrowthDays is the horizon the growth is sized for when the request does not say
+	storageGrowthDays = 365
+	// storageBinlogRetention is binlog_expire_logs_seconds when the family does not set it, the MySQL default
+	storageBinlogRetention = 2592000
+	// storageTmpShare is the share of the data kept for on disk temporary tables, sorts and online DDL
+	storageTmpShare = 0.10
+	// storageTmpMin is the smallest temporary space
+	storageTmpMin = 1073741824
+	// storageFillFactor is how full the PVC may be at the end of the horizon
+	storageFillFactor = 0.80
+	// storageRotationalIops is the IOPS below which the storage is treated as rotational, innodb_flush_neighbors on
+	storageRotationalIops = 1000
+	// storageIopsPerIOThread is the IOPS one innodb read or write I/O thread keeps busy
+	storageIopsPerIOThread = 2000
+	storageGiB             = 1073741824
+)
+
+// Storage is the data and storage class of a request, sizes in bytes
+type Storage struct {
+	DatasetSize  int64 `json:"datasetSize"`
+	GrowthPerDay int64 `json:"growthPerDay,omitempty"`
+	// GrowthDays is the horizon of the growth, 365 when not set
+	GrowthDays int `json:"growthDays,omitempty"`
+	// Iops and ThroughputMBps are what the storage class guarantees
+	Iops           int64 `json:"iops,omitempty"`
+	ThroughputMBps int64 `json:"throughputMBps,omitempty"`
+	// BinlogPerDay is the binary log written a day, the throughput target or the growth when not set
+	BinlogPerDay int64 `json:"binlogPerDay,omitempty"`
+	// HotSetPct (0.0-1.0) is the share of DatasetSize the workload keeps reading, it caps the buffer pool, see workingset.go
+	HotSetPct float64 `json:"hotSetPct,omitempty"`
+	// ReleaseMemory recalculates on the smaller dimension the hot set needs
+	ReleaseMemory bool `json:"releaseMemory,omitempty"`
+}
+
+// StorageReport is the PVC recommendation and what it is made of, sizes in bytes
+type StorageReport struct {
+	Data    int64 `json:"data"`
+	RedoLog int64 `json:"redoLog"`
+	Binlog  int64 `json:"binlog"`
+	Gcache  int64 `json:"gcache,omitempty"`
+	Temp    int64 `json:"temp"`
+	// Free keeps the PVC at storageFillFactor at the end of the horizon
+	Free    int64  `json:"free"`
+	PVCSize int64  `json:"pvcSize"`
+	PVC     string `json:"pvc"`
+	// EffectiveIops are the 16KiB page IOPS the I/O parameters are derived from
+	EffectiveIops int64 `json:"effectiveIops,omitempty"`
+}
+
+// storageParameters are the InnoDB I/O settings of the declared IOPS
+func storageParameters() map[string]map[string]Parameter {
+	return map[string]map[string]Parameter{
+		"configuration_innodb": {
+			"innodb_io_capacity":      throughputParameters()["configuration_innodb"]["innodb_io_capacity"],
+			"innodb_flush_neighbors":  {"innodb_flush_neighbors", "configuration", "innodb", "0", "0", 0, 2, MySQLVersions{V8_0_46, V11_1_1}},
+			"innodb_read_io_threads":  {"innodb_read_io_threads", "configuration", "innodb", "4", "4", 1, 64, MySQLVersions{V8_0_46, V11_1_1}},
+			"innodb_write_io_threads": {"innodb_write_io_threads", "configuration", "innodb", "4", "4", 1, 64, MySQLVersions{V8_0_46, V11_1_1}},
+		},
+	}
+}
+
+func validateStorage(s Storage) error {
+	switch {
+	case s.DatasetSize < 0 || s.GrowthPerDay < 0 || s.BinlogPerDay < 0:
+		return errors.New("storage datasetSize, growthPerDay and binlogPerDay cannot be negative")
+	case s.GrowthDays < 0:
+		return errors.New("storage growthDays cannot be negative")
+	case s.Iops < 0 || s.ThroughputMBps < 0:
+		return errors.New("storage iops and throughputMBps cannot be negative")
+	case s.DatasetSize == 0 && s.Iops == 0:
+		return errors.New("storage needs datasetSize or iops")
+	case s.HotSetPct < 0 || s.HotSetPct > 1:
+		return errors.New("storage hotSetPct must be between 0.0 and 1.0")
+	case s.HotSetPct > 0 && s.DatasetSize == 0:
+		return errors.New("storage hotSetPct needs datasetSize")
+	case s.ReleaseMemory && s.HotSetPct == 0:
+		return errors.New("storage releaseMemory needs hotSetPct")
+	}
+	return nil
+}
+
+func (s Storage) growthDays() int {
+	if s.GrowthDays > 0 {
+		return s.GrowthDays
+	}
+	return storageGrowthDays
+}
+
+// effectiveIops returns the page IOPS of the storage class, the throughput caps them when set
+func (s Storage) effectiveIops() int64 {
+	iops := s.Iops
+	if s.ThroughputMBps > 0 {
+		if byThroughput := s.ThroughputMBps * 1048576 / throughputPageSize; byThroughput < iops {
+			iops = byThroughput
+		}
+	}
+	return iops
+}
+
+// storageIops returns the effective IOPS of the request, 0 when it declares none
+func (c *Configurator) storageIops() int64 {
+	if c.request.Storage == nil {
+		return 0
+	}
+	return c.request.Storage.effectiveIops()
+}
+
+// storageIOCapacity returns the background flushing rate: half the IOPS, at least what a throughput target dirties
+func (c *Configurator) storageIOCapacity() int64 {
+	capacity := c.storageIops() / 2
+	if need := c.throughputIOCapacity(); need > capacity {
+		c.branch("throughput target above half the IOPS")
+		capacity = need
+	}
+	return capacity
+}
+
+func (c *Configurator) paramInnoDBFlushNeighbors(parameter Parameter) Parameter {
+	if c.storageIops() < storageRotationalIops {
+		c.branch("below %d IOPS, rotational", storageRotationalIops)
+		parameter.Value = "1"
+	} else {
+		parameter.Value = "0"
+	}
+	return parameter
+}
+
+// paramInnoDBIOThreads sets innodb_read_io_threads and innodb_write_io_threads, one every storageIopsPerIOThread IOPS
+func (c *Configurator) paramInnoDBIOThreads(parameter Parameter) Parameter {
+	threads := int(math.Ceil(float64(c.storageIops()) / storageIopsPerIOThread))
+	if threads < 4 {
+		threads = 4
+		c.clamped("default", threads)
+	} else if threads > int(parameter.Max) {
+		threads = int(parameter.Max)
+		c.clamped("max", threads)
+	}
+	parameter.Value = strconv.Itoa(threads)
+	return parameter
+}
+
+// getStorageReport sizes the PVC, the redo log and GCache are the calculated ones
+func (c *Configurator) getStorageReport() *StorageReport {
+	s := c.request.Storage
+	report := &StorageReport{EffectiveIops: s.effectiveIops()}
+	if s.DatasetSize == 0 {
+		return report
+	}
+
+	report.Data = s.DatasetSize + s.GrowthPerDay*int64(s.growthDays())
+	report.RedoLog = c.reference.innodbRedoLogDim
+	if c.request.DBType == DbTypePXC {
+		report.Gcache = c.reference.gcache
+	}
+
+	binlogPerDay := s.BinlogPerDay
+	if binlogPerDay == 0 && c.request.Throughput != nil {
+		binlogPerDay = int64(math.Ceil(c.request.Throughput.writeBytesPerSec() * 86400))
+	} else if binlogPerDay == 0 {
+		binlogPerDay = s.GrowthPerDay
+	}
+	report.Binlog = int64(math.Ceil(float64(binlogPerDay) * float64(c.binlogRetention()) / 86400))
+
+	report.Temp = int64(float64(report.Data) * storageTmpShare)
+	if report.Temp < storageTmpMin {
+		report.Temp = storageTmpMin
+	}
+
+	used := report.Data + report.RedoLog + report.Binlog + report.Gcache + report.Temp
+	gib := int64(math.Ceil(float64(used) / storageFillFactor / storageGiB))
+	report.PVCSize = gib * storageGiB
+	report.Free = report.PVCSize - used
+	report.PVC = fmt.Sprintf("%dGi", gib)
+	return report
+}
+
+// binlogRetention returns binlog_expire_logs_seconds as the request sets it, pinned or in the family
+func (c *Configurator) binlogRetention() int64 {
+	if seconds, ok := c.pinnedInt("binlog_expire_logs_seconds"); ok {
+		return seconds
+	}
+	if p, ok := lookupOverridable(c.families, "binlog_expire_logs_seconds"); ok {
+		if seconds, err := strconv.ParseInt(p.Value, 10, 64); err == nil {
+			return seconds
+		}
+	}
+	return storageBinlogRetention
+}
+
+func (r *StorageReport) text() string {
+	if r.PVCSize == 0 {
+		return ""
+	}
+	return fmt.Sprintf("\nStorage PVC = %s (data %d, redo log %d, binlog %d, gcache %d, temp %d, free %d)\n",
+		r.PVC, r.Data, r.RedoLog, r.Binlog, r.Gcache, r.Temp, r.Free)
+}
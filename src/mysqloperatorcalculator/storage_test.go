@@ -0,0 +1,94 @@
+package mysqloperatorcalculator
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestStorage_Calculate(t *testing.T) {
+	gib := int64(1073741824)
+	req := makeRequest(DbTypePXC, 4, LoadTypeSomeWrites, 200)
+	req.Storage = &Storage{DatasetSize: 100 * gib, GrowthPerDay: gib, GrowthDays: 100, Iops: 16000, ThroughputMBps: 125}
+	err, msg, families := runCalculate(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	report := messageReport[*StorageReport](msg, ReportStorage)
+	if report == nil {
+		t.Fatal("the message must carry the storage report")
+	}
+	// 125MB/s of 16KiB pages caps the 16000 IOPS
+	if report.EffectiveIops != 8000 {
+		t.Errorf("effective IOPS = %d, want 8000", report.EffectiveIops)
+	}
+	if report.Data != 200*gib || report.Temp != 20*gib || report.Binlog != 30*gib {
+		t.Errorf("data %d, temp %d and binlog %d do not follow the dataset, growth and retention", report.Data, report.Temp, report.Binlog)
+	}
+	if redo := redoLogCapacityBytes(t, families); report.RedoLog != redo {
+		t.Errorf("redo log = %d, want innodb_redo_log_capacity %d", report.RedoLog, redo)
+	}
+	options := splitProviderOptions(families[FamilyTypeMysql].Groups["configuration_galera"].Parameters["wsrep-provider-options"].Value)
+	if gcache := strconv.FormatInt(report.Gcache, 10); options["gcache.size"] != gcache {
+		t.Errorf("gcache = %s, want gcache.size %s", gcache, options["gcache.size"])
+	}
+	used := report.Data + report.RedoLog + report.Binlog + report.Gcache + report.Temp
+	if report.PVCSize%gib != 0 || float64(used) > float64(report.PVCSize)*storageFillFactor || report.Free != report.PVCSize-used {
+		t.Errorf("PVC %s (%d) must hold %d below the fill factor", report.PVC, report.PVCSize, used)
+	}
+	if !strings.Contains(msg.MText, "Storage PVC = "+report.PVC) {
+		t.Errorf("the message text must carry the PVC: %s", msg.MText)
+	}
+
+	innodb := families[FamilyTypeMysql].Groups["configuration_innodb"].Parameters
+	for name, want := range map[string]string{
+		"innodb_io_capacity":      "4000",
+		"innodb_io_capacity_max":  "8000",
+		"innodb_flush_neighbors":  "0",
+		"innodb_read_io_threads":  "4",
+		"innodb_write_io_threads": "4",
+	} {
+		if v := innodb[name].Value; v != want {
+			t.Errorf("%s = %s, want %s", name, v, want)
+		}
+	}
+
+	b, err := (&MysqlOperatorCalculator{}).GetPXCCROutput(msg, req, families)
+	if err != nil || !strings.Contains(b.String(), "storage: "+report.PVC) {
+		t.Errorf("the custom resource must request the recommended PVC: %v", err)
+	}
+
+	// Slow storage: flushing neighbours, io_capacity never below the default
+	req = makeRequest(DbTypeAsync, 4, LoadTypeSomeWrites, 200)
+	req.Storage = &Storage{Iops: 300}
+	_, msg, families = runCalculate(req)
+	innodb = families[FamilyTypeMysql].Groups["configuration_innodb"].Parameters
+	if innodb["innodb_flush_neighbors"].Value != "1" || innodb["innodb_io_capacity"].Value != "200" || innodb["innodb_io_capacity_max"].Value != "300" {
+		t.Errorf("unexpected I/O parameters for rotational storage: %s %s %s", innodb["innodb_flush_neighbors"].Value,
+			innodb["innodb_io_capacity"].Value, innodb["innodb_io_capacity_max"].Value)
+	}
+	if report = messageReport[*StorageReport](msg, ReportStorage); report == nil || report.PVCSize != 0 {
+		t.Errorf("no PVC without a dataset, got %+v", report)
+	}
+
+	// Without storage the I/O parameters are the load type ones
+	_, _, families = runCalculate(makeRequest(DbTypePXC, 4, LoadTypeSomeWrites, 200))
+	innodb = families[FamilyTypeMysql].Groups["configuration_innodb"].Parameters
+	if _, ok := innodb["innodb_flush_neighbors"]; ok || innodb["innodb_io_capacity_max"].Value != "24000" {
+		t.Error("the storage parameters must only be set for a request with storage")
+	}
+
+	for name, storage := range map[string]Storage{
+		"empty":            {},
+		"negative dataset": {DatasetSize: -1},
+		"negative iops":    {DatasetSize: gib, Iops: -1},
+		"negative days":    {DatasetSize: gib, GrowthDays: -1},
+	} {
+		req := makeRequest(DbTypePXC, 4, LoadTypeSomeWrites, 100)
+		storage := storage
+		req.Storage = &storage
+		if err, _, _ := runCalculate(req); err == nil || !strings.Contains(err.Error(), "storage") {
+			t.Errorf("%s: expected a storage error, got %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,229 @@
+package mysqloperatorcalculator
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+)
+
+const (
+	// throughputReadsPerCore is the reads per second a MySQL core serves
+	throughputReadsPerCore = 4000.0
+	// throughputWritesPerCore is the write transactions per second a MySQL core commits (redo, binlog, replication)
+	throughputWritesPerCore = 1000.0
+	// throughputQueryLatency is the seconds a statement holds its connection, connections = queries/sec × latency
+	throughputQueryLatency = 0.005
+	// throughputRedoMinutes is the minutes of writes the redo log holds when the request does not say
+	throughputRedoMinutes = 60
+	// throughputPageSize is the InnoDB page, a write dirties at least one
+	throughputPageSize = 16384
+)
+
+// Names of the throughput constraints
+const (
+	ConstraintCpu         = "cpu"
+	ConstraintConnections = "connections"
+	ConstraintMemory      = "memory"
+	ConstraintIops        = "iops"
+)
+
+// Throughput is the target load of a request, the calculator sizes the pod for it
+type Throughput struct {
+	ReadsPerSec  float64 `json:"readsPerSec"`
+	WritesPerSec float64 `json:"writesPerSec"`
+	// AvgWriteBytes is the average size of a write transaction in the redo log and binlog
+	AvgWriteBytes int64 `json:"avgWriteBytes"`
+	// RedoMinutes is the minutes of writes the redo log must hold, 60 when not set
+	RedoMinutes int `json:"redoMinutes,omitempty"`
+}
+
+// ThroughputConstraint is one resource the target needs, Utilization above 1 does not fit
+type ThroughputConstraint struct {
+	Name        string  `json:"name"`
+	Need        int64   `json:"need"`
+	Available   int64   `json:"available"`
+	Utilization float64 `json:"utilization"`
+}
+
+type ThroughputReport struct {
+	WriteBytesPerSec int64                  `json:"writeBytesPerSec"`
+	CpuNeed          int64                  `json:"cpuNeed"`
+	Connections      int                    `json:"connections"`
+	RedoLogCapacity  int64                  `json:"redoLogCapacity"`
+	Gcache           int64                  `json:"gcache,omitempty"`
+	IoCapacity       int64                  `json:"ioCapacity"`
+	Constraints      []ThroughputConstraint `json:"constraints"`
+	// Binding is the constraint with the highest utilization
+	Binding string `json:"binding"`
+}
+
+// throughputParameters adds innodb_io_capacity, sized on the writes of the target
+func throughputParameters() map[string]map[string]Parameter {
+	return map[string]map[string]Parameter{
+		"configuration_innodb": {
+			"innodb_io_capacity": {"innodb_io_capacity", "configuration", "innodb", "200", "200", 100, 0, MySQLVersions{V8_0_46, V11_1_1}},
+		},
+	}
+}
+
+func validateThroughput(t Throughput) error {
+	switch {
+	case t.ReadsPerSec < 0 || t.WritesPerSec < 0:
+		return errors.New("throughput readsPerSec and writesPerSec cannot be negative")
+	case t.ReadsPerSec+t.WritesPerSec == 0:
+		return errors.New("throughput needs readsPerSec or writesPerSec")
+	case t.WritesPerSec > 0 && t.AvgWriteBytes <= 0:
+		return errors.New("throughput avgWriteBytes must be set when writesPerSec is")
+	case t.RedoMinutes < 0:
+		return errors.New("throughput redoMinutes cannot be negative")
+	}
+	return nil
+}
+
+// writeBytesPerSec is the redo and binlog volume of the target
+func (t Throughput) writeBytesPerSec() float64 {
+	return t.WritesPerSec * float64(t.AvgWriteBytes)
+}
+
+func (t Throughput) writeRatio() float64 {
+	return t.WritesPerSec / (t.ReadsPerSec + t.WritesPerSec)
+}
+
+// cpuNeed returns the MySQL millicores the target needs
+func (t Throughput) cpuNeed() int64 {
+	return int64(math.Ceil((t.ReadsPerSec/throughputReadsPerCore + t.WritesPerSec/throughputWritesPerCore) * 1000))
+}
+
+// connections returns the connections busy at the target rate, never below MinConnectionNumber
+func (t Throughput) connections() int {
+	connections := int(math.Ceil((t.ReadsPerSec + t.WritesPerSec) * throughputQueryLatency))
+	if connections < MinConnectionNumber {
+		connections = MinConnectionNumber
+	}
+	return connections
+}
+
+func (t Throughput) redoMinutes() int {
+	if t.RedoMinutes > 0 {
+		return t.RedoMinutes
+	}
+	return throughputRedoMinutes
+}
+
+// apply fills the request values left unset: write ratio and connections
+func (t Throughput) apply(request *ConfigurationRequest) {
+	if request.LoadType.Id == 0 && request.WriteRatio == nil {
+		ratio := t.writeRatio()
+		request.WriteRatio = &ratio
+	}
+	if request.Connections == 0 {
+		request.Connections = t.connections()
+	}
+}
+
+// throughputRedoLog returns the redo log holding the requested minutes of writes, inside the parameter bounds
+func (c *Configurator) throughputRedoLog(parameter Parameter) (int64, bool) {
+	t := c.request.Throughput
+	if t == nil || t.WritesPerSec == 0 {
+		return 0, false
+	}
+	c.branch("%d minutes of writes", t.redoMinutes())
+	capacity := int64(math.Ceil(t.writeBytesPerSec() * 60 * float64(t.redoMinutes())))
+	if capacity < int64(parameter.Min) {
+		capacity = int64(parameter.Min)
+		c.clamped("min", int(parameter.Min))
+	} else if parameter.Max > 0 && capacity > int64(parameter.Max) {
+		capacity = int64(parameter.Max)
+		c.clamped("max", int(parameter.Max))
+	}
+	return capacity, true
+}
+
+// throughputIOCapacity returns the pages per second the writes dirty, 0 without a throughput
+func (c *Configurator) throughputIOCapacity() int64 {
+	t := c.request.Throughput
+	if t == nil {
+		return 0
+	}
+	pagesPerWrite := math.Max(1, math.Ceil(float64(t.AvgWriteBytes)/throughputPageSize))
+	return int64(math.Ceil(t.WritesPerSec * pagesPerWrite))
+}
+
+func (c *Configurator) paramInnoDBIOCapacity(parameter Parameter) Parameter {
+	capacity := c.throughputIOCapacity()
+	if c.storageIops() > 0 {
+		capacity = c.storageIOCapacity()
+	}
+	def, _ := strconv.ParseInt(parameter.Default, 10, 64)
+	if capacity < def {
+		capacity = def
+		c.clamped("default", int(def))
+	}
+	parameter.Value = strconv.FormatInt(capacity, 10)
+	return parameter
+}
+
+// throughputCpuOverload reports if the target needs more CPU than the pod gives to MySQL
+func (c *Configurator) throughputCpuOverload() bool {
+	return c.request.Throughput != nil && float64(c.request.Throughput.cpuNeed()) > c.reference.cpusMySQL
+}
+
+// getThroughputReport compares what the target needs with the pod, memory only once the calculation evaluated it
+func (c *Configurator) getThroughputReport(evaluated bool) *ThroughputReport {
+	t := c.request.Throughput
+	report := &ThroughputReport{
+		WriteBytesPerSec: int64(math.Ceil(t.writeBytesPerSec())),
+		CpuNeed:          t.cpuNeed(),
+		Connections:      c.reference.connections,
+		RedoLogCapacity:  c.reference.innodbRedoLogDim,
+		IoCapacity:       c.throughputIOCapacity(),
+	}
+	if c.request.DBType == DbTypePXC {
+		report.Gcache = c.reference.gcache
+	}
+
+	report.Constraints = append(report.Constraints,
+		throughputConstraint(ConstraintCpu, report.CpuNeed, int64(c.reference.cpusMySQL)),
+		throughputConstraint(ConstraintConnections, int64(c.reference.connections), int64(c.reference.loadAdjustmentMax)))
+	if iops := c.storageIops(); iops > 0 {
+		report.Constraints = append(report.Constraints, throughputConstraint(ConstraintIops, report.IoCapacity, iops))
+	}
+	if evaluated {
+		// what the pod allocates outside the buffer pool EvaluateResources judges, plus the floor that buffer pool must keep
+		allocated := int64(c.reference.memory) - c.bufferPoolJudged()
+		need := allocated + int64(c.reference.memory*minLimitByDBType(c.request.DBType))
+		report.Constraints = append(report.Constraints, throughputConstraint(ConstraintMemory, need, int64(c.reference.memory)))
+	}
+
+	binding := report.Constraints[0]
+	for _, constraint := range report.Constraints[1:] {
+		if constraint.Utilization > binding.Utilization {
+			binding = constraint
+		}
+	}
+	report.Binding = binding.Name
+	return report
+}
+
+func throughputConstraint(name string, need int64, available int64) ThroughputConstraint {
+	constraint := ThroughputConstraint{Name: name, Need: need, Available: available}
+	if available > 0 {
+		constraint.Utilization = math.Round(float64(need)/float64(available)*1000) / 1000
+	}
+	return constraint
+}
+
+// text lists the constraints from the most utilized
+func (r *ThroughputReport) text() string {
+	constraints := make([]ThroughputConstraint, len(r.Constraints))
+	copy(constraints, r.Constraints)
+	sort.SliceStable(constraints, func(i, j int) bool { return constraints[i].Utilization > constraints[j].Utilization })
+
+	text := fmt.Sprintf("\nThroughput binding constraint = %s\n", r.Binding)
+	for _, constraint := range constraints {
+		text += fmt.Sprintf("%-12s need %d of %d (%.0f%%)\n", constraint.Name, constraint.Need, constraint.Available, constraint.Utilization*100)
+	}
+	return text
+}
@@ -0,0 +1,110 @@
+package mysqloperatorcalculator
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// The throughput memory constraint must not fit exactly when EvaluateResources judges the pod overutilizing
+func TestThroughputMemoryConstraint(t *testing.T) {
+	cases := []struct {
+		name       string
+		bufferPool int64
+		leftover   int64
+		pinned     bool
+		saved      int64
+		wantFit    bool
+	}{
+		{"above the floor", 5 * testGB, 0, false, 0, true},
+		{"below the floor", 3 * testGB, 0, false, 0, false},
+		{"pinned with memory left", 3 * testGB, 2 * testGB, true, 0, true},
+		{"pinned overcommitted", 6 * testGB, -testGB, true, 0, false},
+		{"capped by the hot set", 2 * testGB, 0, false, 3 * testGB, true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := newTestConfigurator(LoadTypeMostlyReads, DbTypePXC, 50, 4000, 7*testGB)
+			c.request.Throughput = &Throughput{ReadsPerSec: 1000, WritesPerSec: 100, AvgWriteBytes: 1024}
+			c.reference.memory = 8 * testGB
+			c.reference.cpusMySQL = 4000
+			c.reference.innoDBbpSize = tc.bufferPool
+			c.reference.memoryLeftover = tc.leftover
+			c.reference.workingSetSaved = tc.saved
+			if tc.pinned {
+				c.request.Overrides = map[string]string{"innodb_buffer_pool_size": strconv.FormatInt(tc.bufferPool, 10)}
+			}
+
+			var memory ThroughputConstraint
+			for _, constraint := range c.getThroughputReport(true).Constraints {
+				if constraint.Name == ConstraintMemory {
+					memory = constraint
+				}
+			}
+			if fit := memory.Need <= memory.Available; fit != tc.wantFit {
+				t.Errorf("memory need %d of %d: fit %v, want %v", memory.Need, memory.Available, fit, tc.wantFit)
+			}
+			_, over := c.FillResponseMessage(float64(c.bufferPoolJudged())/c.reference.memory, ResponseMessage{}, bytes.Buffer{}, DbTypePXC)
+			if over == tc.wantFit {
+				t.Errorf("FillResponseMessage overutilizing %v, the memory constraint fit %v", over, tc.wantFit)
+			}
+		})
+	}
+}
+
+func TestThroughput_Calculate(t *testing.T) {
+	req := makeRequest(DbTypePXC, ConnectionDimension, 0, 0)
+	req.Throughput = &Throughput{ReadsPerSec: 20000, WritesPerSec: 2000, AvgWriteBytes: 2048}
+	var conf Configuration
+	conf.Init()
+	var moc MysqlOperatorCalculator
+	incoming := moc.Init(req, conf)
+	if incoming.WriteRatio == nil || incoming.LoadType.Id != LoadTypeMostlyReads || incoming.Connections != 110 {
+		t.Errorf("write ratio, load type and connections must come from the target: %v %+v %d", incoming.WriteRatio, incoming.LoadType, incoming.Connections)
+	}
+
+	err, msg, families := moc.GetCalculate()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	report := messageReport[*ThroughputReport](msg, ReportThroughput)
+	if report == nil {
+		t.Fatal("the message must carry the throughput report")
+	}
+	if report.CpuNeed != 7000 || float64(report.CpuNeed) > float64(moc.IncomingRequest.Dimension.MysqlCpu) {
+		t.Errorf("the scaled pod must give MySQL the %d millicores the target needs, got %d", report.CpuNeed, moc.IncomingRequest.Dimension.MysqlCpu)
+	}
+	if report.Binding != ConstraintCpu || len(report.Constraints) != 3 {
+		t.Errorf("cpu must be the binding constraint, got %s %+v", report.Binding, report.Constraints)
+	}
+	// 2000 writes of 2KiB: 60 minutes in the redo log, 30 in GCache
+	if redo := redoLogCapacityBytes(t, families); redo != 4096000*3600 || report.Gcache != 4096000*1800 {
+		t.Errorf("redo log %d and gcache %d must hold 60 and 30 minutes of writes", redo, report.Gcache)
+	}
+	if v := families[FamilyTypeMysql].Groups["configuration_innodb"].Parameters["innodb_io_capacity"].Value; v != "2000" {
+		t.Errorf("innodb_io_capacity = %s, want one page per write", v)
+	}
+
+	// A fixed pod too small for the target keeps the connections and names the constraint
+	req = makeRequest(DbTypePXC, 2, 0, 0)
+	req.Throughput = &Throughput{ReadsPerSec: 20000, WritesPerSec: 2000, AvgWriteBytes: 2048}
+	_, msg, _ = runCalculate(req)
+	if report = messageReport[*ThroughputReport](msg, ReportThroughput); msg.MType != OverutilizingI || report == nil || report.Binding != ConstraintCpu || report.Connections != 110 {
+		t.Errorf("expected an overutilizing verdict bound by cpu, got %d %+v", msg.MType, report)
+	}
+
+	for name, target := range map[string]Throughput{
+		"no rates":          {AvgWriteBytes: 1024},
+		"negative":          {ReadsPerSec: -1, WritesPerSec: 10, AvgWriteBytes: 1024},
+		"no write size":     {WritesPerSec: 10},
+		"negative duration": {WritesPerSec: 10, AvgWriteBytes: 1024, RedoMinutes: -5},
+	} {
+		req := makeRequest(DbTypePXC, 4, LoadTypeSomeWrites, 100)
+		target := target
+		req.Throughput = &target
+		if err, _, _ := runCalculate(req); err == nil || !strings.Contains(err.Error(), "throughput") {
+			t.Errorf("%s: expected a throughput error, got %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package mysqloperatorcalculator
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// Cluster modes
+const (
+	ModeSinglePrimary = "single-primary"
+	ModeMultiPrimary  = "multi-primary"
+)
+
+// MaxClusterNodes is the largest group replication group, the same limit is kept for Galera
+const MaxClusterNodes = 9
+
+func validateTopology(request ConfigurationRequest) error {
+	if request.Mode != "" && request.Mode != ModeSinglePrimary && request.Mode != ModeMultiPrimary {
+		return fmt.Errorf("Mode %s is not correct. Supported modes are: %s, %s", request.Mode, ModeSinglePrimary, ModeMultiPrimary)
+	}
+	if request.Nodes < 0 {
+		return fmt.Errorf("nodes cannot be negative")
+	}
+	if request.DBType == DbTypeAsync {
+		if request.Mode == ModeMultiPrimary {
+			return fmt.Errorf("Mode %s is not supported with DB Type %s", ModeMultiPrimary, DbTypeAsync)
+		}
+		return nil
+	}
+	if nodes := request.clusterNodes(); nodes < 3 || nodes > MaxClusterNodes || nodes%2 == 0 {
+		return fmt.Errorf("nodes must be an odd number from 3 to %d for DB Type %s, an even group loses the quorum when half of it fails", MaxClusterNodes, request.DBType)
+	}
+	return nil
+}
+
+// clusterNodes returns the members of the cluster, source and replicas for async, the segments hold them when nodes is not set
+func (r ConfigurationRequest) clusterNodes() int {
+	if r.Nodes > 0 {
+		return r.Nodes
+	}
+	if r.Network != nil && r.Network.members() > 0 {
+		return r.Network.members()
+	}
+	return DefaultClusterNodes
+}
+
+func (r ConfigurationRequest) multiPrimary() bool {
+	return r.Mode == ModeMultiPrimary
+}
+
+// survivingNodes returns the members left when one is lost, each one takes a share of its connections
+func (r ConfigurationRequest) survivingNodes() int {
+	if nodes := r.clusterNodes() - 1; nodes > 0 {
+		return nodes
+	}
+	return 1
+}
+
+// topology reports if the request describes the cluster, the members or the mode
+func (r ConfigurationRequest) topology() bool {
+	return r.Nodes > 0 || r.Mode != ""
+}
+
+// writers returns the members taking writes: the primary, every member in multi-primary
+func (c *Configurator) writers() int {
+	if c.request.multiPrimary() {
+		return c.request.clusterNodes()
+	}
+	return 1
+}
+
+// topologyFactor is √(nodes / DefaultClusterNodes): the more members, the more likely one is slow
+// and the longer the group takes to agree on it
+func (c *Configurator) topologyFactor() float64 {
+	return math.Sqrt(float64(c.request.clusterNodes()) / DefaultClusterNodes)
+}
+
+// topologyParameters are the group replication mode switches and the writer quota
+func topologyParameters() map[string]map[string]Parameter {
+	return map[string]map[string]Parameter{
+		"configuration_groupReplication": {
+			"loose_group_replication_single_primary_mode":               {"loose_group_replication_single_primary_mode", "configuration", "groupReplication", "ON", "ON", 0, 1, MySQLVersions{V8_0_46, V11_1_1}},
+			"loose_group_replication_enforce_update_everywhere_checks":  {"loose_group_replication_enforce_update_everywhere_checks", "configuration", "groupReplication", "OFF", "OFF", 0, 1, MySQLVersions{V8_0_46, V11_1_1}},
+			"loose_group_replication_flow_control_member_quota_percent": {"loose_group_replication_flow_control_member_quota_percent", "configuration", "groupReplication", "0", "0", 0, 100, MySQLVersions{V8_0_46, V11_1_1}},
+		},
+	}
+}
+
+// topologyProviderParams adds the Galera options of a request with a mode, single-primary flow control
+// is not scaled by Galera with the members
+func (c *Configurator) topologyProviderParams() {
+	if c.request.Mode == "" {
+		return
+	}
+	c.providerParams["gcs.fc_single_primary"] = ProviderParam{"gcs.fc_single_primary", map[bool]string{true: "NO", false: "YES"}[c.request.multiPrimary()], -1, 0, 0, 0}
+}
+
+// scaleProviderByNodes scales the suspect and inactive timeouts and the single-primary fc_limit by topologyFactor.
+// A multi-primary Galera already multiplies fc_limit by √members
+func (c *Configurator) scaleProviderByNodes(key string, value int64) int64 {
+	switch key {
+	case "evs.suspect_timeout", "evs.inactive_timeout":
+	case "gcs.fc_limit":
+		if c.request.multiPrimary() {
+			return value
+		}
+	default:
+		return value
+	}
+	return int64(math.Ceil(float64(value) * c.topologyFactor()))
+}
+
+func (c *Configurator) getGroupReplicationTopology() {
+	if !c.request.topology() {
+		return
+	}
+	group := c.families[FamilyTypeMysql].Groups["configuration_groupReplication"]
+	onOff := func(on bool) func(Parameter) Parameter {
+		return func(p Parameter) Parameter {
+			p.Value = map[bool]string{true: "ON", false: "OFF"}[on]
+			return p
+		}
+	}
+	c.traced(group, "loose_group_replication_single_primary_mode",
+		onOff(!c.request.multiPrimary()), "ON for single-primary", traceMode)
+	c.traced(group, "loose_group_replication_enforce_update_everywhere_checks",
+		onOff(c.request.multiPrimary()), "ON for multi-primary", traceMode)
+	c.traced(group, "loose_group_replication_flow_control_member_quota_percent",
+		c.paramGroupReplicationMemberQuota, "multi-primary: the quota shared by the members, single-primary: 0", traceMode, traceNodes)
+	c.traced(group, "loose_group_replication_paxos_single_leader",
+		c.paramGroupReplicationPaxosSingleLeader, "ON for single-primary, one round trip to the leader, OFF for multi-primary", traceMode)
+	c.families[FamilyTypeMysql].Groups["configuration_groupReplication"] = group
+}
+
+// paramGroupReplicationPaxosSingleLeader follows the mode, a multi-primary group has no leader
+func (c *Configurator) paramGroupReplicationPaxosSingleLeader(parameter Parameter) Parameter {
+	parameter.Value = "ON"
+	if c.request.multiPrimary() {
+		parameter.Value = "OFF"
+	}
+	return parameter
+}
+
+// paramGroupReplicationMemberQuota gives every writer of a multi-primary group its share of the flow control quota
+func (c *Configurator) paramGroupReplicationMemberQuota(parameter Parameter) Parameter {
+	if !c.request.multiPrimary() {
+		parameter.Value = "0"
+		return parameter
+	}
+	parameter.Value = strconv.Itoa(100 / c.request.clusterNodes())
+	return parameter
+}
@@ -0,0 +1,162 @@
+package mysqloperatorcalculator
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestTopology_Calculate(t *testing.T) {
+	provider := func(req ConfigurationRequest) map[string]string {
+		t.Helper()
+		err, _, families := runCalculate(req)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return splitProviderOptions(families[FamilyTypeMysql].Groups["configuration_galera"].Parameters["wsrep-provider-options"].Value)
+	}
+	seconds := func(option string) int {
+		v, _ := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(option, "PT"), "S"))
+		return v
+	}
+
+	base := provider(makeRequest(DbTypePXC, 4, LoadTypeSomeWrites, 200))
+	if _, ok := base["gcs.fc_single_primary"]; ok {
+		t.Error("gcs.fc_single_primary must only be set with a mode")
+	}
+	req := makeRequest(DbTypePXC, 4, LoadTypeSomeWrites, 200)
+	req.Nodes = 3
+	req.Mode = ModeSinglePrimary
+	three := provider(req)
+	if three["gcs.fc_limit"] != base["gcs.fc_limit"] || three["evs.suspect_timeout"] != base["evs.suspect_timeout"] || three["gcs.fc_single_primary"] != "YES" {
+		t.Errorf("3 single-primary nodes must keep the default values: %v", three)
+	}
+
+	req.Nodes = 7
+	seven := provider(req)
+	// 7 members scale the 3 members values by √(7 / 3)
+	scaled := func(v int) int { return int(math.Ceil(float64(v) * math.Sqrt(7.0/3))) }
+	for _, key := range []string{"evs.suspect_timeout", "evs.inactive_timeout"} {
+		if got, want := seconds(seven[key]), scaled(seconds(base[key])); got != want {
+			t.Errorf("%s = %s, want %d seconds", key, seven[key], want)
+		}
+	}
+	if fc, _ := strconv.Atoi(base["gcs.fc_limit"]); seven["gcs.fc_limit"] != strconv.Itoa(scaled(fc)) {
+		t.Errorf("gcs.fc_limit = %s, want %d", seven["gcs.fc_limit"], scaled(fc))
+	}
+	req.Mode = ModeMultiPrimary
+	multi := provider(req)
+	if multi["gcs.fc_limit"] != base["gcs.fc_limit"] || multi["gcs.fc_single_primary"] != "NO" {
+		t.Errorf("Galera scales a multi-primary fc_limit itself: %v", multi)
+	}
+
+	// Proxy sized for the survivors, CR for the members
+	req = makeRequest(DbTypePXC, 4, LoadTypeSomeWrites, 300)
+	req.Nodes = 5
+	_, msg, families := runCalculate(req)
+	if v := families[FamilyTypeProxy].Groups[GroupNameHAProxy].Parameters["maxconn"].Value; v != "1500" {
+		t.Errorf("maxconn = %s, want the connections of the 5 members", v)
+	}
+	cfg, err := haproxyCfg(req, families)
+	if err != nil || !strings.Contains(cfg.String(), "check inter 10000 rise 1 fall 2 weight 1 maxconn 375\n") {
+		t.Errorf("every backend server must take its share of a lost member, 300 × 5 / 4: %v\n%s", err, cfg.String())
+	}
+	b, err := (&MysqlOperatorCalculator{}).GetPXCCROutput(msg, req, families)
+	if err != nil || !strings.Contains(b.String(), "size: 5\n") {
+		t.Errorf("the custom resource must carry the 5 members: %v", err)
+	}
+
+	// Group replication
+	req = makeRequest(DbTypeGroupReplication, 4, LoadTypeSomeWrites, 200)
+	req.Nodes = 5
+	req.Mode = ModeMultiPrimary
+	_, _, families = runCalculate(req)
+	gr := families[FamilyTypeMysql].Groups["configuration_groupReplication"].Parameters
+	for name, want := range map[string]string{
+		"loose_group_replication_single_primary_mode":               "OFF",
+		"loose_group_replication_enforce_update_everywhere_checks":  "ON",
+		"loose_group_replication_flow_control_member_quota_percent": "20",
+	} {
+		if v := gr[name].Value; v != want {
+			t.Errorf("%s = %s, want %s", name, v, want)
+		}
+	}
+	// nodes alone set the group replication flow control
+	req = makeRequest(DbTypeGroupReplication, 4, LoadTypeSomeWrites, 200)
+	req.Nodes = 5
+	_, _, families = runCalculate(req)
+	if v, ok := families[FamilyTypeMysql].Groups["configuration_groupReplication"].Parameters["loose_group_replication_flow_control_member_quota_percent"]; !ok || v.Value != "0" {
+		t.Errorf("5 single-primary members must set the flow control quota to 0, got %+v", v)
+	}
+
+	for name, tc := range map[string]struct {
+		dbtype string
+		nodes  int
+		mode   string
+	}{
+		"even":         {DbTypePXC, 4, ""},
+		"too many":     {DbTypeGroupReplication, 11, ""},
+		"single node":  {DbTypePXC, 1, ""},
+		"unknown mode": {DbTypePXC, 3, "active-active"},
+		"async multi":  {DbTypeAsync, 2, ModeMultiPrimary},
+		"negative":     {DbTypeAsync, -1, ""},
+	} {
+		req := makeRequest(tc.dbtype, 4, LoadTypeSomeWrites, 100)
+		req.Nodes = tc.nodes
+		req.Mode = tc.mode
+		if err, _, _ := runCalculate(req); err == nil {
+			t.Errorf("%s: expected a topology error", name)
+		}
+	}
+	// A source with one replica is a valid async topology
+	req = makeRequest(DbTypeAsync, 4, LoadTypeSomeWrites, 100)
+	req.Nodes = 2
+	if err, _, _ := runCalculate(req); err != nil {
+		t.Errorf("async with 2 nodes: %v", err)
+	}
+}
+
+func TestParamGroupReplicationFlowControlPeriod_Nodes(t *testing.T) {
+	cases := []struct {
+		nodes      int
+		loadFactor float32
+		want       string
+	}{
+		{3, 0.6, "2"},
+		{9, 0.6, "4"}, // 2 × √3
+		{9, 0.1, "5"}, // capped at max
+		{3, 0.95, "1"},
+	}
+	for _, tc := range cases {
+		c := newTestConfigurator(LoadTypeSomeWrites, DbTypeGroupReplication, 100, 4000, 8*testGB)
+		c.request.Nodes = tc.nodes
+		c.reference.loadFactor = tc.loadFactor
+		p := Parameter{Default: "1", Min: 1, Max: 5}
+		if got := c.paramGroupReplicationFlowControlPeriod(p).Value; got != tc.want {
+			t.Errorf("nodes=%d loadFactor=%.2f: got %s, want %s", tc.nodes, tc.loadFactor, got, tc.want)
+		}
+	}
+}
+
+func TestParamGroupReplicationMemberExpelTimeout_Nodes(t *testing.T) {
+	cases := []struct {
+		nodes      int
+		loadFactor float32
+		want       string
+	}{
+		{3, 0.5, "8"},
+		{5, 0.5, "10"}, // 7.5 × √(5 / 3)
+		{9, 0.5, "13"}, // 7.5 × √3
+		{3, 0.2, "5"},  // never below the default
+	}
+	for _, tc := range cases {
+		c := newTestConfigurator(LoadTypeSomeWrites, DbTypeGroupReplication, 100, 4000, 8*testGB)
+		c.request.Nodes = tc.nodes
+		c.reference.loadFactor = tc.loadFactor
+		p := Parameter{Value: "15", Default: "5", Min: 0, Max: 3600}
+		if got := c.paramGroupReplicationMemberExpelTimeout(p).Value; got != tc.want {
+			t.Errorf("nodes=%d loadFactor=%.2f: got %s, want %s", tc.nodes, tc.loadFactor, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package mysqloperatorcalculator
+
+import (
+	"fmt"
+	"math"
+)
+
+// workingSetHeadroom is the buffer pool above the hot set: adaptive hash index, change buffer, pages of the cold data
+const workingSetHeadroom = 0.25
+
+type WorkingSetReport struct {
+	HotSet int64 `json:"hotSet"`
+	// Cap is the hot set plus workingSetHeadroom
+	Cap        int64 `json:"cap"`
+	BufferPool int64 `json:"bufferPool"`
+	// Saved is the memory the buffer pool would have taken above Cap
+	Saved int64 `json:"saved"`
+	Fits  bool  `json:"fits"`
+	// MissRatio is the share of the hot set reads expected to go to disk when it does not fit
+	MissRatio float64 `json:"missRatio,omitempty"`
+	// Released is the smaller dimension the saved memory was given back with
+	Released *Dimension `json:"released,omitempty"`
+}
+
+// hotSet returns the bytes of the dataset the workload keeps reading, 0 when not declared
+func (s Storage) hotSet() int64 {
+	return int64(math.Ceil(float64(s.DatasetSize) * s.HotSetPct))
+}
+
+// workingSetCap returns the largest buffer pool the hot set needs
+func (c *Configurator) workingSetCap() (int64, bool) {
+	s := c.request.Storage
+	if s == nil || s.HotSetPct == 0 {
+		return 0, false
+	}
+	return int64(math.Ceil(float64(s.hotSet()) * (1 + workingSetHeadroom))), true
+}
+
+// capBufferPoolByWorkingSet returns the buffer pool within the hot set cap, the memory above it stays left over
+func (c *Configurator) capBufferPoolByWorkingSet(bufferPool int64) int64 {
+	c.reference.workingSetSaved = 0
+	bpCap, ok := c.workingSetCap()
+	if !ok || bufferPool <= bpCap {
+		return bufferPool
+	}
+	c.branch("capped at the hot set plus %.0f%%", workingSetHeadroom*100)
+	c.reference.workingSetSaved = bufferPool - bpCap
+	c.reference.memoryLeftover += c.reference.workingSetSaved
+	return bpCap
+}
+
+func (c *Configurator) getWorkingSetReport() *WorkingSetReport {
+	bpCap, _ := c.workingSetCap()
+	report := &WorkingSetReport{
+		HotSet:     c.request.Storage.hotSet(),
+		Cap:        bpCap,
+		BufferPool: c.reference.innoDBbpSize,
+		Saved:      c.reference.workingSetSaved,
+	}
+	report.Fits = report.BufferPool >= report.HotSet
+	if !report.Fits {
+		report.MissRatio = math.Round((1-float64(report.BufferPool)/float64(report.HotSet))*10000) / 10000
+	}
+	return report
+}
+
+func (r *WorkingSetReport) text() string {
+	text := ""
+	if r.Saved > 0 {
+		text += fmt.Sprintf("\nWorking set: buffer pool capped at %d for a hot set of %d, memory saved = %d\n", r.Cap, r.HotSet, r.Saved)
+	}
+	if !r.Fits {
+		text += fmt.Sprintf("\n!!!! The hot set of %d bytes does not fit the buffer pool of %d bytes, expected buffer pool miss ratio %.1f%% !!!!\n",
+			r.HotSet, r.BufferPool, r.MissRatio*100)
+	}
+	return text
+}
+
+// releaseWorkingSetMemory recalculates on the smallest open dimension, same CPU, whose buffer pool still reaches the hot set cap.
+// The original result is kept when no smaller dimension fits
+func (moc *MysqlOperatorCalculator) releaseWorkingSetMemory(err error, message ResponseMessage, families map[string]Family) (error, ResponseMessage, map[string]Family) {
+	original := moc.IncomingRequest.Dimension
+	step := float64(MemoryIncrement * 1_000_000)
+
+	var fitErr error
+	var fit ResponseMessage
+	var fitFamilies map[string]Family
+	var fitDimension *Dimension
+	memory := original.MemoryBytes - float64(message.Reports[ReportWorkingSet].(*WorkingSetReport).Saved)
+	for memory < original.MemoryBytes {
+		dimension := moc.Conf.CalculateOpenDimension(Dimension{
+			Id:          DimensionOpen,
+			Name:        "working set",
+			Cpu:         original.Cpu,
+			MemoryBytes: memory,
+			Memory:      original.formatMemoryMB(memory),
+		})
+		moc.IncomingRequest.Dimension = dimension
+		releasedErr, released, releasedFamilies := moc.getCalculateInt()
+		report, _ := released.Reports[ReportWorkingSet].(*WorkingSetReport)
+		fits := releasedErr == nil && released.MType != OverutilizingI && report != nil && report.BufferPool >= report.Cap
+
+		switch {
+		case fits:
+			fitErr, fit, fitFamilies, fitDimension = releasedErr, released, releasedFamilies, &dimension
+			if float64(report.Saved) < step {
+				memory = original.MemoryBytes
+			} else {
+				memory -= float64(report.Saved)
+			}
+		case fitDimension != nil:
+			// lowered too much, the last fitting dimension is within what it saved
+			memory = original.MemoryBytes
+		default:
+			memory += step
+		}
+	}
+
+	if fitDimension == nil {
+		moc.IncomingRequest.Dimension = original
+		return err, message, families
+	}
+	moc.IncomingRequest.Dimension = *fitDimension
+	fit.Reports[ReportWorkingSet].(*WorkingSetReport).Released = fitDimension
+	fit.MText += fmt.Sprintf("\n!!!! Memory given back to the hot set: %s instead of %s !!!!\n", fitDimension.Memory, original.formatMemoryMB(original.MemoryBytes))
+	return fitErr, fit, fitFamilies
+}
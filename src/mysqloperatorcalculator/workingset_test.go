@@ -0,0 +1,74 @@
+package mysqloperatorcalculator
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestWorkingSet_Calculate(t *testing.T) {
+	gib := int64(1073741824)
+	_, baseline, _ := runCalculate(makeRequest(DbTypePXC, 6, LoadTypeSomeWrites, 200))
+
+	// A small hot set caps the buffer pool, the verdict is the one of the uncapped pod
+	req := makeRequest(DbTypePXC, 6, LoadTypeSomeWrites, 200)
+	req.Storage = &Storage{DatasetSize: 100 * gib, HotSetPct: 0.02}
+	err, msg, families := runCalculate(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ws := messageReport[*WorkingSetReport](msg, ReportWorkingSet)
+	if ws == nil {
+		t.Fatal("the message must carry the working set report")
+	}
+	if ws.HotSet != 2*gib || ws.Cap != ws.HotSet+ws.HotSet/4 || ws.BufferPool != ws.Cap || !ws.Fits {
+		t.Errorf("buffer pool must be capped at the hot set plus headroom: %+v", *ws)
+	}
+	if ws.Saved != baseline.Breakdown.Memory.Mysql.BufferPool-ws.Cap || msg.MType != baseline.MType {
+		t.Errorf("saved %d must be the buffer pool above the cap, verdict %d must not change", ws.Saved, msg.MType)
+	}
+	if v := families[FamilyTypeMysql].Groups["configuration_innodb"].Parameters["innodb_buffer_pool_size"].Value; v != strconv.FormatInt(ws.Cap, 10) {
+		t.Errorf("innodb_buffer_pool_size = %s, want the cap %d", v, ws.Cap)
+	}
+
+	// Given back: a smaller open dimension whose buffer pool still reaches the cap
+	req.Storage.ReleaseMemory = true
+	err, msg, _ = runCalculate(req)
+	ws = messageReport[*WorkingSetReport](msg, ReportWorkingSet)
+	if err != nil || ws == nil || ws.Released == nil {
+		t.Fatalf("expected a released dimension: %v %+v", err, ws)
+	}
+	released := ws.Released
+	if released.Id != DimensionOpen || released.Cpu != baseline.Breakdown.Cpu.Total || released.MemoryBytes >= float64(baseline.Breakdown.Memory.Total) {
+		t.Errorf("released dimension must keep the cpu with less memory: %+v", *released)
+	}
+	if ws.BufferPool != ws.Cap || float64(ws.Saved) >= float64(MemoryIncrement*1_000_000) {
+		t.Errorf("the released pod must be within one memory increment of the cap: %+v", *ws)
+	}
+
+	// A hot set larger than the buffer pool is reported with the miss ratio
+	req = makeRequest(DbTypePXC, 6, LoadTypeSomeWrites, 200)
+	req.Storage = &Storage{DatasetSize: 100 * gib, HotSetPct: 1}
+	_, msg, _ = runCalculate(req)
+	ws = messageReport[*WorkingSetReport](msg, ReportWorkingSet)
+	if ws == nil || ws.Fits || ws.Saved != 0 || ws.HotSet != 100*gib || !strings.Contains(msg.MText, "does not fit the buffer pool") {
+		t.Fatalf("expected a miss ratio warning: %+v", ws)
+	}
+	if want := math.Round((1-float64(ws.BufferPool)/float64(ws.HotSet))*10000) / 10000; ws.MissRatio != want {
+		t.Errorf("miss ratio = %v, want the share of the hot set above the buffer pool %v", ws.MissRatio, want)
+	}
+
+	for name, storage := range map[string]Storage{
+		"above 1":         {DatasetSize: gib, HotSetPct: 1.5},
+		"without dataset": {Iops: 1000, HotSetPct: 0.5},
+		"release alone":   {DatasetSize: gib, ReleaseMemory: true},
+	} {
+		req := makeRequest(DbTypePXC, 4, LoadTypeSomeWrites, 100)
+		storage := storage
+		req.Storage = &storage
+		if err, _, _ := runCalculate(req); err == nil || !strings.Contains(err.Error(), "storage") {
+			t.Errorf("%s: expected a storage error, got %v", name, err)
+		}
+	}
+}
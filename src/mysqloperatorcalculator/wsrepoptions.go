@@ -0,0 +1,85 @@
+package mysqloperatorcalculator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// providerUnits are the seconds of the duration units a provider option can be given in
+var providerUnits = map[string]int64{"S": 1, "M": 60, "H": 3600}
+
+// unit returns the duration unit of the option, S or M, "" for the options without one
+func (p ProviderParam) unit() string {
+	switch p.Literal {
+	case "PT%sS":
+		return "S"
+	case "PT%sM":
+		return "M"
+	}
+	return ""
+}
+
+// format returns the option value as the provider reads it
+func (p ProviderParam) format(value int64) string {
+	if value < 0 {
+		return p.Literal
+	}
+	return fmt.Sprintf(p.Literal, strconv.FormatInt(value, 10))
+}
+
+// parse returns the value of a pinned option in the unit of the option: a number, or for the duration
+// options an ISO 8601 duration PT<n>S, PT<n>M or PT<n>H that converts to a whole number of that unit
+func (p ProviderParam) parse(value string) (int64, error) {
+	if v, err := strconv.ParseInt(value, 10, 64); err == nil {
+		if v < 0 {
+			return 0, fmt.Errorf("Override %s must be a positive integer, got %q", p.Name, value)
+		}
+		return v, nil
+	}
+	unit := p.unit()
+	if unit == "" {
+		return 0, fmt.Errorf("Override %s must be a positive integer, got %q", p.Name, value)
+	}
+	upper := strings.ToUpper(value)
+	if len(upper) < 4 || !strings.HasPrefix(upper, "PT") {
+		return 0, fmt.Errorf("Override %s must be a number of %s or a duration like PT30S, got %q", p.Name, providerUnitName(unit), value)
+	}
+	seconds, ok := providerUnits[upper[len(upper)-1:]]
+	v, err := strconv.ParseInt(upper[2:len(upper)-1], 10, 64)
+	if !ok || err != nil || v < 0 {
+		return 0, fmt.Errorf("Override %s must be a number of %s or a duration like PT30S, got %q", p.Name, providerUnitName(unit), value)
+	}
+	if seconds*v%providerUnits[unit] != 0 {
+		return 0, fmt.Errorf("Override %s=%s is not a whole number of %s", p.Name, value, providerUnitName(unit))
+	}
+	return seconds * v / providerUnits[unit], nil
+}
+
+func providerUnitName(unit string) string {
+	return map[string]string{"S": "seconds", "M": "minutes"}[unit]
+}
+
+// pinnedProvider returns the pinned value of the option in its unit
+func (c *Configurator) pinnedProvider(key string) (int64, bool) {
+	value, ok := c.pinned(key)
+	if !ok {
+		return 0, false
+	}
+	v, err := c.providerParams[key].parse(value)
+	return v, err == nil
+}
+
+// providerOptions returns the provider options as parameters in the versions of wsrep-provider-options,
+// Min and Max are the RMin/RMax of the option. A pinned PT1M is output in the unit of the option, PT60S
+func (c *Configurator) providerOptions(versions MySQLVersions) map[string]Parameter {
+	options := make(map[string]Parameter, len(c.providerParams))
+	for key, param := range c.providerParams {
+		defValue := param.Literal
+		if param.Value >= 0 {
+			defValue = param.format(param.Defvalue)
+		}
+		options[key] = Parameter{key, "configuration", "galera", param.format(param.Value), defValue, uint64(param.RMin), uint64(param.RMax), versions}
+	}
+	return options
+}
@@ -0,0 +1,82 @@
+package mysqloperatorcalculator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestProviderOptions_Calculate(t *testing.T) {
+	req := makeRequest(DbTypePXC, 4, LoadTypeSomeWrites, 200)
+	req.Overrides = map[string]string{
+		"evs.suspect_timeout":     "PT1M",
+		"evs.inactive_timeout":    "90",
+		"evs.stats_report_period": "PT60S",
+	}
+	err, msg, families := runCalculate(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	galera := families[FamilyTypeMysql].Groups["configuration_galera"]
+	options, ok := galera.Options["wsrep-provider-options"]
+	if !ok {
+		t.Fatal("the provider options are missing")
+	}
+	providerOptions := galera.Parameters["wsrep-provider-options"]
+	joined := splitProviderOptions(providerOptions.Value)
+	if len(joined) != len(options) {
+		t.Errorf("wsrep-provider-options holds %d options, the structured ones %d", len(joined), len(options))
+	}
+	for name, p := range options {
+		if joined[name] != p.Value {
+			t.Errorf("%s = %q structured, %q in wsrep-provider-options", name, p.Value, joined[name])
+		}
+		if p.Mysqlversions != providerOptions.Mysqlversions {
+			t.Errorf("%s versions %+v, want the ones of wsrep-provider-options", name, p.Mysqlversions)
+		}
+	}
+	for name, want := range map[string]string{
+		"evs.suspect_timeout":     "PT60S",
+		"evs.inactive_timeout":    "PT90S",
+		"evs.stats_report_period": "PT1M",
+	} {
+		if p := options[name]; p.Value != want || !galera.IsPinnedOption("wsrep-provider-options", name) {
+			t.Errorf("%s = %q pinned=%v, want %s pinned", name, p.Value, galera.IsPinnedOption("wsrep-provider-options", name), want)
+		}
+	}
+	// 128 × the load factor of the request
+	if p := options["gcs.fc_limit"]; galera.IsPinnedOption("wsrep-provider-options", "gcs.fc_limit") || p.Value != "5" || p.Default != "16" || p.Max != 128 {
+		t.Errorf("gcs.fc_limit = %+v, want the calculated 5 with its default and range", p)
+	}
+
+	var moc MysqlOperatorCalculator
+	b, err := moc.GetMyCnfOutput(msg, req, families)
+	if err != nil || strings.Contains(b.String(), "\nevs.suspect_timeout") {
+		t.Errorf("the option file must carry the provider options only in wsrep-provider-options, err=%v", err)
+	}
+	_, _, families = runCalculate(makeRequest(DbTypeGroupReplication, 4, LoadTypeSomeWrites, 200))
+	for key, group := range families[FamilyTypeMysql].Groups {
+		if len(group.Options) > 0 {
+			t.Errorf("%s carries provider options, they are pxc only", key)
+		}
+	}
+
+	for value, wantErr := range map[string]bool{
+		"PT30S": false,
+		"pt1m":  false,
+		"PT2H":  true,
+		"PT0S":  true,
+		"30s":   true,
+		"PTS":   true,
+		"-5":    true,
+	} {
+		req := makeRequest(DbTypePXC, 4, LoadTypeSomeWrites, 200)
+		req.Overrides = map[string]string{"evs.suspect_timeout": value}
+		if err, _, _ := runCalculate(req); (err != nil) != wantErr {
+			t.Errorf("evs.suspect_timeout=%s: err=%v, want error %v", value, err, wantErr)
+		}
+	}
+	req.Overrides = map[string]string{"evs.stats_report_period": "PT90S"}
+	if err, _, _ := runCalculate(req); err == nil || !strings.Contains(err.Error(), "whole number of minutes") {
+		t.Errorf("PT90S is not a whole number of minutes: %v", err)
+	}
+}
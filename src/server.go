@@ -21,16 +21,18 @@ func main() {
 	var (
 		//port    int
 		//ip      string
-		helpB    bool
-		version  bool
-		help     HelpText
-		loglevel string
+		helpB      bool
+		version    bool
+		help       HelpText
+		loglevel   string
+		dimensions string
 	)
 	port := flag.Int("port", 8080, "Port to serve")
 	ip := flag.String("address", "0.0.0.0", "Ip address")
 	flag.BoolVar(&helpB, "help", false, "for help")
 	flag.BoolVar(&version, "version", false, "to get product version")
 	flag.StringVar(&loglevel, "loglevel", "DEBUG", "log level default debug (ERROR|INFO|DEBUG)")
+	flag.StringVar(&dimensions, "dimensions", "", "dimension catalog file (.json|.yaml|.yml) replacing the built-in dimensions")
 	flag.Parse()
 
 	//initialize help
@@ -59,12 +61,26 @@ func main() {
 
 	}
 
+	//load the dimension catalog if any, the server must not start with an invalid one
+	if dimensions != "" {
+		catalog, err := MO.LoadDimensionCatalog(dimensions)
+		if err == nil {
+			err = MO.SetDimensionCatalog(catalog)
+		}
+		if err != nil {
+			log.Error(err)
+			os.Exit(1)
+		}
+		log.Infof("Loaded %d dimensions from %s", len(catalog), dimensions)
+	}
+
 	//set server address (need to come from configuration parameter)
 	server := http.Server{Addr: *ip + ":" + strconv.Itoa(*port)}
 
 	//define API handlers
 	http.HandleFunc("/calculator", handleRequestCalculator)
 	http.HandleFunc("/supported", handleRequestSupported)
+	http.HandleFunc("/audit", handleRequestAudit)
 	err := server.ListenAndServe()
 	if err != nil {
 		log.Error(err)
@@ -157,7 +173,7 @@ func handleGetCalculate(writer http.ResponseWriter, request *http.Request) error
 		}
 		return nil
 	}
-	if ConfRequest.Dimension.MemoryBytes == 0 && ConfRequest.Dimension.Id != 998 {
+	if ConfRequest.Dimension.MemoryBytes == 0 && ConfRequest.Dimension.Id != 998 && ConfRequest.InstanceType == "" {
 		var errConv error
 		ConfRequest.Dimension.MemoryBytes, errConv = ConfRequest.Dimension.ConvertMemoryToBytes(ConfRequest.Dimension.Memory)
 		if errConv != nil {
@@ -169,7 +185,7 @@ func handleGetCalculate(writer http.ResponseWriter, request *http.Request) error
 	}
 
 	// Before going to the configurator we check the incoming request and IF is not ok we return an error message
-	if ConfRequest.Dimension.Id == 0 || ConfRequest.LoadType.Id == 0 || ConfRequest.Mysqlversion.Major == 0 {
+	if (ConfRequest.Dimension.Id == 0 && ConfRequest.InstanceType == "") || (ConfRequest.LoadType.Id == 0 && ConfRequest.GlobalStatus == "" && ConfRequest.WriteRatio == nil && ConfRequest.Throughput == nil) || ConfRequest.Mysqlversion.Major == 0 {
 		var message = ""
 		if ConfRequest.Mysqlversion.Major == 0 {
 			message = "Missing MySQL Version"
@@ -180,7 +196,7 @@ func handleGetCalculate(writer http.ResponseWriter, request *http.Request) error
 			return err
 		}
 		return nil
-	} else if ConfRequest.Dimension.Id == MO.DimensionOpen && (ConfRequest.Dimension.Cpu == 0 || ConfRequest.Dimension.MemoryBytes == 0 || ConfRequest.Mysqlversion.Major == 0) {
+	} else if ConfRequest.Dimension.Id == MO.DimensionOpen && ConfRequest.InstanceType == "" && (ConfRequest.Dimension.Cpu == 0 || ConfRequest.Dimension.MemoryBytes == 0 || ConfRequest.Mysqlversion.Major == 0) {
 		err := returnErrorMessage(writer, request, ConfRequest, responseMsg, families, "Open dimension request missing CPU, Memory value or MySQL Version"+string(body[:]))
 		if err != nil {
 			return err
@@ -214,6 +230,70 @@ func handleGetCalculate(writer http.ResponseWriter, request *http.Request) error
 
 }
 
+func handleRequestAudit(writer http.ResponseWriter, request *http.Request) {
+	var err error
+	switch request.Method {
+	case "POST":
+		err = handleGetAudit(writer, request)
+	}
+	if err != nil {
+		http.Error(writer, err.Error(), http.StatusInternalServerError)
+		log.Error(err)
+	}
+}
+
+// here we return the audit of an option file for a request like:
+// { "dbtype": "pxc", "dimension": {"id": 4}, "loadtype": {"id": 2}, "mysqlversion": {"major": 8, "minor": 0, "patch": 46}, "mycnf": "[mysqld]\n..."}
+
+func handleGetAudit(writer http.ResponseWriter, request *http.Request) error {
+	var responseMsg MO.ResponseMessage
+	var conf MO.Configuration
+	var auditRequest MO.AuditRequest
+
+	body, readErr := io.ReadAll(request.Body)
+	if readErr != nil || len(body) == 0 {
+		return returnErrorMessage(writer, request, auditRequest.ConfigurationRequest, responseMsg, nil, "Empty request body")
+	}
+	if err := json.Unmarshal(body, &auditRequest); err != nil {
+		return returnErrorMessage(writer, request, auditRequest.ConfigurationRequest, responseMsg, nil, "Malformed JSON: "+err.Error())
+	}
+	ConfRequest := auditRequest.ConfigurationRequest
+	if auditRequest.MyCnf == "" || ConfRequest.Mysqlversion.Major == 0 {
+		return returnErrorMessage(writer, request, ConfRequest, responseMsg, nil, "Audit request missing mycnf or MySQL Version")
+	}
+	if ConfRequest.Dimension.MemoryBytes == 0 && ConfRequest.InstanceType == "" && ConfRequest.Dimension.Memory != "" {
+		var errConv error
+		ConfRequest.Dimension.MemoryBytes, errConv = ConfRequest.Dimension.ConvertMemoryToBytes(ConfRequest.Dimension.Memory)
+		if errConv != nil {
+			return returnErrorMessage(writer, request, ConfRequest, responseMsg, nil, "Possible Malformed request "+errConv.Error())
+		}
+	}
+
+	conf.Init()
+	var moc MO.MysqlOperatorCalculator
+	ConfRequest = moc.Init(ConfRequest, conf)
+
+	err, report := moc.GetAudit(strings.NewReader(auditRequest.MyCnf))
+	if err != nil {
+		return returnErrorMessage(writer, request, ConfRequest, responseMsg, nil, err.Error())
+	}
+
+	var b bytes.Buffer
+	contentType := "application/json"
+	if ConfRequest.Output == MO.ResultOutputFormatJson {
+		b, err = moc.GetAuditJSONOutput(report, ConfRequest)
+	} else {
+		b, err = moc.GetAuditHumanOutput(report, ConfRequest)
+		contentType = "text/plain"
+	}
+	if err != nil {
+		return err
+	}
+	writer.Header().Set("Content-Type", contentType)
+	writer.Write(b.Bytes())
+	return nil
+}
+
 func exitWithCode(errorCode int) {
 	log.Debug("Error execution with code ", errorCode)
 	//os.Exit(errorCode)
@@ -235,9 +315,23 @@ func ReturnResponse(writer http.ResponseWriter, request *http.Request, ConfReque
 	var b bytes.Buffer
 	var err error
 	var moc MO.MysqlOperatorCalculator
-	if ConfRequest.Output == "json" {
+	contentType := "application/json"
+	switch ConfRequest.Output {
+	case MO.ResultOutputFormatJson:
 		b, err = moc.GetJSONOutput(message, ConfRequest, families)
-	} else {
+	case MO.ResultOutputFormatMyCnf:
+		b, err = moc.GetMyCnfOutput(message, ConfRequest, families)
+		contentType = "text/plain"
+	case MO.ResultOutputFormatPXCCR:
+		b, err = moc.GetPXCCROutput(message, ConfRequest, families)
+		contentType = "application/yaml"
+	case MO.ResultOutputFormatPSCR:
+		b, err = moc.GetPSCROutput(message, ConfRequest, families)
+		contentType = "application/yaml"
+	case MO.ResultOutputFormatHAProxyCfg:
+		b, err = moc.GetHAProxyCfgOutput(message, ConfRequest, families)
+		contentType = "text/plain"
+	default:
 		b, err = moc.GetHumanOutput(message, ConfRequest, families)
 	}
 	if err != nil {
@@ -245,7 +339,7 @@ func ReturnResponse(writer http.ResponseWriter, request *http.Request, ConfReque
 	}
 
 	// Return the information
-	writer.Header().Set("Content-Type", "application/json")
+	writer.Header().Set("Content-Type", contentType)
 	writer.Write(b.Bytes())
 	return nil
 }